@@ -0,0 +1,116 @@
+package vers
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Alias returns a minecraft.Protocol with the protocol ID and version passed that translates packets using the
+// protocol passed. Aliases may be used to accept a hotfix release that changed the protocol ID but not the format
+// of any packets, or to accept two patch versions with a single translator, without copying a whole package:
+//
+//	vers.WithProtocols(mv671.Protocol{}, vers.Alias(mv671.Protocol{}, 672, "1.20.81"))
+//
+// Note that the protocol of a player that joined using an alias, as returned by ProtocolOf, is the alias, so its
+// ID should be used to find out what version the player joined with rather than its type.
+func Alias(proto minecraft.Protocol, id int32, ver string) minecraft.Protocol {
+	return aliasProtocol{Protocol: proto, id: id, ver: ver}
+}
+
+// aliasProtocol is a minecraft.Protocol that translates packets using another protocol under a different ID and
+// version.
+type aliasProtocol struct {
+	minecraft.Protocol
+	id  int32
+	ver string
+}
+
+// ID ...
+func (p aliasProtocol) ID() int32 {
+	return p.id
+}
+
+// Ver ...
+func (p aliasProtocol) Ver() string {
+	return p.ver
+}
+
+// knownNetworks holds the networks that Vers can advertise the version of the newest accepted protocol on and
+// apply UnsupportedPolicies on, keyed by their ID. On other networks, the version of the latest protocol is always
+// advertised and clients joining with a protocol that is not accepted are always shown the default screen.
+var knownNetworks = map[string]minecraft.Network{"raknet": minecraft.RakNet{}}
+
+// listenNetworks holds the IDs of the networks registered by listenNetwork, so that each is only registered once.
+var (
+	listenNetworksMu sync.Mutex
+	listenNetworks   = map[string]struct{}{}
+)
+
+// listenNetwork returns the ID of the network to listen on for the accepted protocols passed. If one of these
+// protocols is newer than the latest protocol, for example an Alias for a hotfix release, a network is returned
+// that advertises its version in the status response instead of that of the latest protocol. If an
+// UnsupportedPolicy is set, a network is returned of which the listeners allow clients to be rejected by it.
+func (v *Vers) listenNetwork(accepted []minecraft.Protocol) string {
+	n, ok := knownNetworks[v.network]
+	if !ok {
+		return v.network
+	}
+	id := v.network
+	if len(accepted) > 0 {
+		if newest := slices.MaxFunc(accepted, compareProtocols); newest.ID() > protocol.CurrentProtocol {
+			id = fmt.Sprintf("%v/%v/%v", id, newest.ID(), newest.Ver())
+			n = statusNetwork{Network: n, id: newest.ID(), ver: newest.Ver()}
+		}
+	}
+	if len(v.unsupported) > 0 || v.unsupportedPolicy != nil {
+		id += "/unsupported"
+		n = unsupportedNetwork{Network: n}
+	}
+	if id == v.network {
+		return id
+	}
+
+	listenNetworksMu.Lock()
+	defer listenNetworksMu.Unlock()
+	if _, ok := listenNetworks[id]; !ok {
+		listenNetworks[id] = struct{}{}
+		minecraft.RegisterNetwork(id, n)
+	}
+	return id
+}
+
+// statusNetwork is a minecraft.Network of which the listeners advertise a different protocol ID and version than
+// that of the latest protocol.
+type statusNetwork struct {
+	minecraft.Network
+	id  int32
+	ver string
+}
+
+// Listen ...
+func (n statusNetwork) Listen(address string) (minecraft.NetworkListener, error) {
+	l, err := n.Network.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	return statusListener{NetworkListener: l, id: n.id, ver: n.ver}, nil
+}
+
+// statusListener is a minecraft.NetworkListener that replaces the protocol ID and version of the latest protocol
+// in its pong data.
+type statusListener struct {
+	minecraft.NetworkListener
+	id  int32
+	ver string
+}
+
+// PongData ...
+func (l statusListener) PongData(data []byte) {
+	latest := fmt.Sprintf(";%v;%v;", protocol.CurrentProtocol, protocol.CurrentVersion)
+	l.NetworkListener.PongData(bytes.Replace(data, []byte(latest), []byte(fmt.Sprintf(";%v;%v;", l.id, l.ver)), 1))
+}
@@ -0,0 +1,78 @@
+package vers
+
+import (
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestAlias tests that a client may join using an alias of a protocol, and that the alias is reported as the
+// protocol of the connection.
+func TestAlias(t *testing.T) {
+	alias := Alias(mv671.Protocol{}, 672, "1.20.81")
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv671.Protocol{}, alias)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+
+	if proto := conn.Protocol(); proto.ID() != 672 || proto.Ver() != "1.20.81" {
+		t.Errorf("expected protocol 672 (1.20.81), got %v (%v)", proto.ID(), proto.Ver())
+	}
+	if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: "Hello, server!"}); err != nil {
+		t.Fatalf("write to server: %v", err)
+	}
+	if err := expectText(conn.Conn, "Hello, server!"); err != nil {
+		t.Fatalf("server: %v", err)
+	}
+}
+
+// TestListenNetwork tests that the version of the newest accepted protocol is advertised if it is newer than the
+// latest protocol, that clients may be rejected if an UnsupportedPolicy is set, and that unknown networks are used
+// as they are.
+func TestListenNetwork(t *testing.T) {
+	hotfix := Alias(minecraft.DefaultProtocol, 686, "1.21.2")
+	for _, tc := range []struct {
+		v        *Vers
+		accepted []minecraft.Protocol
+		want     string
+	}{
+		{New(":0"), []minecraft.Protocol{mv671.Protocol{}}, "raknet"},
+		{New(":0"), []minecraft.Protocol{mv671.Protocol{}, hotfix}, "raknet/686/1.21.2"},
+		{New(":0", WithUnsupportedPolicy(DisconnectUnsupported("Outdated!"))), []minecraft.Protocol{mv671.Protocol{}, hotfix}, "raknet/686/1.21.2/unsupported"},
+		{New(":0", WithNetwork("unknown")), []minecraft.Protocol{hotfix}, "unknown"},
+	} {
+		if got := tc.v.listenNetwork(tc.accepted); got != tc.want {
+			t.Errorf("expected network %v, got %v", tc.want, got)
+		}
+	}
+
+	rec := &pongRecorder{}
+	statusListener{NetworkListener: rec, id: 686, ver: "1.21.2"}.PongData([]byte("MCPE;Server;685;1.21.0;0;20;1;Vers;Creative;1;19132;19132;"))
+	if want := "MCPE;Server;686;1.21.2;0;20;1;Vers;Creative;1;19132;19132;"; string(rec.data) != want {
+		t.Errorf("expected pong data %v, got %v", want, string(rec.data))
+	}
+}
+
+// pongRecorder is a minecraft.NetworkListener that records the pong data set.
+type pongRecorder struct {
+	minecraft.NetworkListener
+	data []byte
+}
+
+// PongData ...
+func (r *pongRecorder) PongData(data []byte) {
+	r.data = data
+}
@@ -0,0 +1,37 @@
+package vers
+
+import (
+	"context"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// Dial dials a server running the version of the protocol passed, such as a 1.20.0 server when passing
+// mv589.Protocol{}, as a client. The network is the ID of a network registered with minecraft.RegisterNetwork,
+// usually "raknet". Dial spawns the client in the world before returning, after which packets may be read from
+// and written to the connection returned. These packets are always those of the latest protocol: packets are
+// converted from and to the protocol passed by the connection.
+// Dial gives up joining after 30 seconds. DialContext may be used to control the timeout and other settings.
+func Dial(network, address string, proto minecraft.Protocol) (*minecraft.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	return DialContext(ctx, minecraft.Dialer{}, network, address, proto)
+}
+
+// DialContext dials a server in the same way as Dial, using the minecraft.Dialer passed for settings such as the
+// identity of the client or the token source used to log in to XBOX Live. The Protocol of the minecraft.Dialer
+// is replaced with the protocol passed. The deadline of the context.Context passed is used for the maximum amount
+// of time that joining can take.
+func DialContext(ctx context.Context, d minecraft.Dialer, network, address string, proto minecraft.Protocol) (*minecraft.Conn, error) {
+	d.Protocol = proto
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,114 @@
+package vers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestDial tests dialing a server of every supported version as a client, checking that the packets read from
+// and written to the connection are always those of the latest protocol.
+func TestDial(t *testing.T) {
+	for _, proto := range protocols {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			testDial(t, proto)
+		})
+	}
+}
+
+// testDial tests dialing a server that runs the version of the protocol passed.
+func testDial(t *testing.T, proto minecraft.Protocol) {
+	l, err := minecraft.ListenConfig{AuthenticationDisabled: true, AcceptedProtocols: []minecraft.Protocol{proto}}.Listen(verstest.Network, ":0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	sent := []packet.Packet{
+		&packet.PlayerList{ActionType: packet.PlayerListActionRemove, Entries: []protocol.PlayerListEntry{{UUID: uuid.New()}}},
+		&packet.MobEffect{EntityRuntimeID: 1, Operation: packet.MobEffectAdd, EffectType: packet.EffectSpeed, Duration: 200},
+		&packet.SetActorMotion{EntityRuntimeID: 1, Velocity: mgl32.Vec3{0, 1, 0}},
+		&packet.ShowStoreOffer{OfferID: "offer"},
+		&packet.LevelChunk{SubChunkCount: protocol.SubChunkRequestModeLimitless, RawPayload: []byte{0}},
+	}
+	received := make(chan packet.Packet, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		conn := c.(*minecraft.Conn)
+		defer conn.Close()
+		if err := conn.StartGame(minecraft.GameData{WorldName: "World", EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+			return
+		}
+		for _, pk := range sent {
+			_ = conn.WritePacket(pk)
+		}
+		_ = conn.Flush()
+		for {
+			pk, err := conn.ReadPacket()
+			if err != nil {
+				return
+			}
+			if _, ok := pk.(*packet.PlayerAuthInput); ok {
+				received <- pk
+				return
+			}
+		}
+	}()
+
+	client, err := Dial(verstest.Network, l.Addr().String(), proto)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer client.Close()
+	if client.Protocol().ID() != proto.ID() {
+		t.Errorf("expected client to use protocol %v, got %v", proto.ID(), client.Protocol().ID())
+	}
+
+	if err := client.WritePacket(&packet.PlayerAuthInput{Position: mgl32.Vec3{0, 64, 0}, Tick: 1}); err != nil {
+		t.Fatalf("write packet: %v", err)
+	}
+	select {
+	case pk := <-received:
+		if pk.(*packet.PlayerAuthInput).Position != (mgl32.Vec3{0, 64, 0}) {
+			t.Errorf("expected PlayerAuthInput position to be kept, got %v", pk.(*packet.PlayerAuthInput).Position)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("server did not receive PlayerAuthInput")
+	}
+
+	for _, want := range sent {
+		if err := expectPacket(client, want); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// expectPacket reads packets from the connection passed until a packet with the same ID as the packet passed is
+// read, and checks that it is of the same type as the packet passed.
+func expectPacket(conn *minecraft.Conn, want packet.Packet) error {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			return fmt.Errorf("read %T: %w", want, err)
+		}
+		if pk.ID() != want.ID() {
+			continue
+		}
+		if reflect.TypeOf(pk) != reflect.TypeOf(want) {
+			return fmt.Errorf("expected %T, got %T", want, pk)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+// Command mvproxy runs a multi-version proxy in front of a server running the latest version of Minecraft:
+// Bedrock Edition. Clients of every supported version may join the proxy and are relayed to the upstream
+// server on the latest protocol.
+//
+// The upstream server must have authentication disabled, as the proxy connects on behalf of its clients. The
+// upstream server should therefore not be reachable other than through the proxy.
+package main
+
+import (
+	"flag"
+
+	"github.com/oomph-ac/mv"
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	var (
+		addr     = flag.String("listen", ":19132", "address to listen for clients on")
+		upstream = flag.String("upstream", "127.0.0.1:19133", "address of the upstream server")
+		name     = flag.String("name", "Multi-version Proxy", "name of the proxy displayed in the server list")
+		noAuth   = flag.Bool("no-auth", false, "accept clients that are not logged in to XBOX Live")
+		debug    = flag.Bool("debug", false, "enable debug logging")
+	)
+	flag.Parse()
+
+	log := logrus.New()
+	log.Formatter = &logrus.TextFormatter{ForceColors: true}
+	if *debug {
+		log.Level = logrus.DebugLevel
+	}
+
+	v := vers.New(*addr,
+		vers.WithProtocols(multiversion.Legacy()...),
+		vers.WithListenConfig(minecraft.ListenConfig{AuthenticationDisabled: *noAuth}),
+	)
+	p := vers.NewProxy(v, vers.ProxyConfig{
+		Upstream: *upstream,
+		Name:     *name,
+		Log:      log,
+	})
+	if err := p.ListenAndServe(); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,119 @@
+package vers
+
+import (
+	"sync"
+
+	"github.com/df-mc/dragonfly/server/player"
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// Conn is a connection accepted by the listener of a Vers instance. It is passed to Dragonfly as session.Conn and
+// may be obtained for a player using ConnOf, so that game code can find out which protocol the player joined with.
+type Conn struct {
+	*minecraft.Conn
+	conns *connections
+}
+
+// connections holds the open connections accepted by a single listener of a Vers instance, keyed by their remote
+// address. Each listener has its own connections, so that connections of different listeners never replace each
+// other.
+type connections struct {
+	m sync.Map
+}
+
+// listeners holds the connections of every open listener of a Vers instance.
+var listeners sync.Map
+
+// newConnections returns the connections of a new listener and adds them to the open listeners.
+func newConnections() *connections {
+	conns := &connections{}
+	listeners.Store(conns, struct{}{})
+	return conns
+}
+
+// close removes the connections from the open listeners, forgetting all connections that were not yet closed.
+func (conns *connections) close() {
+	listeners.Delete(conns)
+}
+
+// add wraps the minecraft.Conn passed and adds it to the open connections.
+func (conns *connections) add(c *minecraft.Conn) *Conn {
+	conn := &Conn{Conn: c, conns: conns}
+	conns.m.Store(c.RemoteAddr().String(), conn)
+	return conn
+}
+
+// lookup returns the open connection with the remote address passed.
+func (conns *connections) lookup(addr string) (*Conn, bool) {
+	conn, ok := conns.m.Load(addr)
+	if !ok {
+		return nil, false
+	}
+	return conn.(*Conn), true
+}
+
+// Protocol returns the minecraft.Protocol that the connection joined with, such as mv589.Protocol{} for a 1.20.0
+// client or minecraft.DefaultProtocol for a client on the latest version. Its ID and Ver methods may be used to
+// gate features by client version.
+func (c *Conn) Protocol() minecraft.Protocol {
+	return unwrap(c.Conn.Protocol())
+}
+
+// unwrap returns the protocol wrapped by the listener of a Vers instance to intercept packets or select
+// resource packs. Aliases are not unwrapped, as their ID and version are those the client joined with.
+func unwrap(proto minecraft.Protocol) minecraft.Protocol {
+	if p, ok := proto.(interceptedProtocol); ok {
+		proto = p.Protocol
+	}
+	if p, ok := proto.(packProtocol); ok {
+		proto = p.Protocol
+	}
+	return proto
+}
+
+// Close closes the connection and removes it from the open connections.
+func (c *Conn) Close() error {
+	c.forget()
+	return c.Conn.Close()
+}
+
+// forget removes the connection from the open connections of its listener. A newer connection with the same
+// remote address is kept.
+func (c *Conn) forget() {
+	c.conns.m.CompareAndDelete(c.RemoteAddr().String(), c)
+}
+
+// ConnOf returns the Conn that the player passed is connected with. False is returned if the player is not
+// connected to the server, or if it joined using a listener not created by a Vers instance.
+func ConnOf(p *player.Player) (*Conn, bool) {
+	addr := p.Addr()
+	if addr == nil {
+		return nil, false
+	}
+	var conn *Conn
+	listeners.Range(func(conns, _ any) bool {
+		// Connections of different listeners may share a remote address, so the identity of the player is checked
+		// as well.
+		if c, ok := conns.(*connections).lookup(addr.String()); ok && c.IdentityData().Identity == p.UUID().String() {
+			conn = c
+			return false
+		}
+		return true
+	})
+	return conn, conn != nil
+}
+
+// ProtocolOf returns the minecraft.Protocol that the player passed joined with. False is returned if the player is
+// not connected using a listener of a Vers instance. The ID and Ver methods of the protocol returned may be used
+// to gate features that are not supported by older clients:
+//
+//	if proto, ok := vers.ProtocolOf(p); ok && proto.ID() < 630 {
+//		// The crafter was added in 1.20.50.
+//	}
+func ProtocolOf(p *player.Player) (minecraft.Protocol, bool) {
+	conn, ok := ConnOf(p)
+	if !ok {
+		return nil, false
+	}
+	return conn.Protocol(), true
+}
@@ -0,0 +1,130 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestConnProtocol tests that the protocol that a connection joined with may be found, even if its packets are
+// intercepted, and that connections are forgotten once closed.
+func TestConnProtocol(t *testing.T) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...), WithInterceptors(NopInterceptor{})).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, mv649.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, ok := conn.Protocol().(mv649.Protocol); !ok {
+		t.Errorf("expected protocol %T, got %T", mv649.Protocol{}, conn.Protocol())
+	}
+	conns := l.(listener).conns
+	if c, ok := conns.lookup(conn.RemoteAddr().String()); !ok || c != conn {
+		t.Errorf("expected connection to be registered under its remote address")
+	}
+
+	_ = conn.Close()
+	if _, ok := conns.lookup(conn.RemoteAddr().String()); ok {
+		t.Errorf("expected connection to be removed after closing")
+	}
+}
+
+// TestConnForgotten tests that connections are forgotten when disconnected by the listener, and that the
+// connections of a listener are forgotten once it is closed.
+func TestConnForgotten(t *testing.T) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	conns := l.(listener).conns
+
+	client, conn, err := join(l, mv649.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	_ = l.Disconnect(conn, "disconnected")
+	if _, ok := conns.lookup(conn.RemoteAddr().String()); ok {
+		t.Errorf("expected connection to be removed after disconnecting")
+	}
+
+	other, _, err := join(l, mv649.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	_ = l.Close()
+	if _, ok := listeners.Load(conns); ok {
+		t.Errorf("expected connections of the listener to be removed after closing it")
+	}
+}
+
+// TestConnEmulation tests that packets written to a connection of a client that does not support them are
+// emulated, and that the client decodes the packets written in their place.
+func TestConnEmulation(t *testing.T) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, mv622.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+
+	for _, pk := range []packet.Packet{
+		&packet.SetPlayerInventoryOptions{},
+		&packet.ContainerOpen{WindowID: 1, ContainerType: protocol.ContainerTypeCrafter, ContainerPosition: protocol.BlockPos{1, 2, 3}},
+		&packet.Text{TextType: packet.TextTypeRaw, Message: "done"},
+	} {
+		if err := conn.WritePacket(pk); err != nil {
+			t.Fatalf("write %T: %v", pk, err)
+		}
+	}
+
+	var opened bool
+	_ = client.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for {
+		pk, err := client.ReadPacket()
+		if err != nil {
+			t.Fatalf("read packet: %v", err)
+		}
+		switch pk := pk.(type) {
+		case *packet.SetPlayerInventoryOptions:
+			t.Errorf("expected SetPlayerInventoryOptions to be dropped")
+		case *packet.ContainerOpen:
+			if pk.WindowID != 1 || pk.ContainerType != protocol.ContainerTypeDispenser || pk.ContainerPosition != (protocol.BlockPos{1, 2, 3}) {
+				t.Errorf("expected crafter to be opened as dispenser, got %+v", pk)
+			}
+			opened = true
+		case *packet.Text:
+			if pk.Message != "done" {
+				t.Errorf("expected message %q, got %q", "done", pk.Message)
+			}
+			if !opened {
+				t.Error("expected ContainerOpen before Text")
+			}
+			return
+		}
+	}
+}
@@ -7,7 +7,9 @@ require (
 	github.com/df-mc/worldupgrader v1.0.15
 	github.com/go-gl/mathgl v1.1.0
 	github.com/google/uuid v1.6.0
+	github.com/muhammadmuzzammil1998/jsonc v1.0.0
 	github.com/pelletier/go-toml v1.9.5
+	github.com/sandertv/go-raknet v1.14.0
 	github.com/sandertv/gophertunnel v1.38.0
 	github.com/sirupsen/logrus v1.9.3
 )
@@ -20,9 +22,7 @@ require (
 	github.com/go-jose/go-jose/v3 v3.0.3 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
-	github.com/muhammadmuzzammil1998/jsonc v1.0.0 // indirect
 	github.com/rogpeppe/go-internal v1.12.0 // indirect
-	github.com/sandertv/go-raknet v1.14.0 // indirect
 	github.com/segmentio/fasthash v1.0.3 // indirect
 	go.uber.org/atomic v1.11.0 // indirect
 	golang.org/x/crypto v0.24.0 // indirect
@@ -36,4 +36,4 @@ require (
 
 replace github.com/sandertv/gophertunnel v1.38.0 => github.com/oomph-ac/gophertunnel v0.0.0-20240616183157-9a90927ebb22
 
-replace github.com/sandertv/go-raknet v1.13.0 => github.com/tedacmc/tedac-raknet v0.0.4
+replace github.com/sandertv/go-raknet v1.14.0 => github.com/tedacmc/tedac-raknet v0.0.4
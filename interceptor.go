@@ -0,0 +1,101 @@
+package vers
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Direction is the direction in which a packet is translated.
+type Direction uint8
+
+const (
+	// ToLatest is the direction of packets read from a connection, which are translated from the protocol of the
+	// connection to the latest protocol using ConvertToLatest.
+	ToLatest Direction = iota
+	// FromLatest is the direction of packets written to a connection, which are translated from the latest
+	// protocol to the protocol of the connection using ConvertFromLatest.
+	FromLatest
+)
+
+// InterceptContext holds information on the packet being intercepted by an Interceptor.
+type InterceptContext struct {
+	// Conn is the connection that the packet is read from or written to.
+	Conn *minecraft.Conn
+	// Protocol is the ID of the protocol that the packet is translated from or to.
+	Protocol int32
+	// Direction is the direction in which the packet is translated.
+	Direction Direction
+}
+
+// Interceptor intercepts packets of a connection before and after they are translated by its protocol. For
+// packets read from the connection, the original packet is the packet of the protocol of the connection and the
+// translated packets are those of the latest protocol. For packets written, this is the other way around.
+type Interceptor interface {
+	// Before is called with the original packet before it is translated. The packet may be modified, which
+	// changes the packet that is translated. Before returns false to cancel the packet, in which case it is not
+	// translated and no further interceptors are called.
+	Before(ctx *InterceptContext, pk packet.Packet) bool
+	// After is called with the original packet and the packets it was translated into. The packets returned
+	// replace the translated packets, so that packets may be modified, dropped or injected. Returning an empty
+	// slice cancels the packet. After is also called for packets that are not translated into any packet, such as
+	// the CraftingEvent packet of clients older than 1.20.50, so that these may still be handled.
+	// Note that a minecraft.Conn writes all packets translated from a single packet using the header of the
+	// original packet, so packets written to a connection must not be translated into more than one packet.
+	// Packets should only be injected for packets read from a connection.
+	After(ctx *InterceptContext, pk packet.Packet, translated []packet.Packet) []packet.Packet
+}
+
+// NopInterceptor is an Interceptor that does nothing. It may be embedded in an Interceptor implementation to
+// only implement the methods that are needed.
+type NopInterceptor struct{}
+
+// Before ...
+func (NopInterceptor) Before(*InterceptContext, packet.Packet) bool { return true }
+
+// After ...
+func (NopInterceptor) After(_ *InterceptContext, _ packet.Packet, translated []packet.Packet) []packet.Packet {
+	return translated
+}
+
+// Intercept returns a minecraft.Protocol that translates packets using the protocol passed, calling the
+// interceptors passed before and after every translation. Interceptors are called in the order they are passed
+// in, each being passed the packets returned by the previous one. Intercept may be used to intercept packets of
+// a single protocol, or of a connection dialed using Dial.
+func Intercept(proto minecraft.Protocol, interceptors ...Interceptor) minecraft.Protocol {
+	if len(interceptors) == 0 {
+		return proto
+	}
+	return interceptedProtocol{Protocol: proto, interceptors: interceptors}
+}
+
+// interceptedProtocol is a minecraft.Protocol that calls a list of interceptors around its translation.
+type interceptedProtocol struct {
+	minecraft.Protocol
+	interceptors []Interceptor
+}
+
+// ConvertToLatest ...
+func (p interceptedProtocol) ConvertToLatest(pk packet.Packet, conn *minecraft.Conn) []packet.Packet {
+	return p.intercept(ToLatest, pk, conn, p.Protocol.ConvertToLatest)
+}
+
+// ConvertFromLatest ...
+func (p interceptedProtocol) ConvertFromLatest(pk packet.Packet, conn *minecraft.Conn) []packet.Packet {
+	return p.intercept(FromLatest, pk, conn, p.Protocol.ConvertFromLatest)
+}
+
+// intercept translates the packet passed using the convert function passed, calling the interceptors of the
+// protocol before and after.
+func (p interceptedProtocol) intercept(dir Direction, pk packet.Packet, conn *minecraft.Conn, convert func(packet.Packet, *minecraft.Conn) []packet.Packet) []packet.Packet {
+	ctx := &InterceptContext{Conn: conn, Protocol: p.ID(), Direction: dir}
+	for _, i := range p.interceptors {
+		if !i.Before(ctx, pk) {
+			return nil
+		}
+	}
+	translated := convert(pk, conn)
+	for _, i := range p.interceptors {
+		translated = i.After(ctx, pk, translated)
+	}
+	return translated
+}
@@ -0,0 +1,195 @@
+package vers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	v622packet "github.com/oomph-ac/mv/multiversion/mv622/packet"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	v671packet "github.com/oomph-ac/mv/multiversion/mv671/packet"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestInterceptors tests cancelling, modifying and injecting packets using interceptors, and checks that the
+// interceptors are called in order with both forms of the packets translated.
+func TestInterceptors(t *testing.T) {
+	rec := &recordingInterceptor{}
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv671.Protocol{}), WithInterceptors(
+		rec,
+		textInterceptor{suffix: " a", inject: true},
+		textInterceptor{suffix: " b"},
+	)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, mv671.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+
+	for _, message := range []string{"cancel", "Hello, server!"} {
+		if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: message}); err != nil {
+			t.Fatalf("write to server: %v", err)
+		}
+	}
+	if err := expectText(conn.Conn, "Hello, server! a b"); err != nil {
+		t.Fatalf("server: %v", err)
+	}
+	if err := expectText(conn.Conn, "injected b"); err != nil {
+		t.Fatalf("server: %v", err)
+	}
+
+	if err := conn.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: "Hello, client!"}); err != nil {
+		t.Fatalf("write to client: %v", err)
+	}
+	if err := expectText(client, "Hello, client! a b"); err != nil {
+		t.Fatalf("client: %v", err)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.protocol != (mv671.Protocol{}).ID() {
+		t.Errorf("expected protocol %v, got %v", mv671.Protocol{}.ID(), rec.protocol)
+	}
+	if _, ok := rec.original.(*v671packet.Text); !ok {
+		t.Errorf("expected original packet of type %T, got %T", &v671packet.Text{}, rec.original)
+	}
+	if _, ok := rec.translated.(*packet.Text); !ok {
+		t.Errorf("expected translated packet of type %T, got %T", &packet.Text{}, rec.translated)
+	}
+}
+
+// recordingInterceptor records the last Text packet read from a connection, in both its original and translated
+// form.
+type recordingInterceptor struct {
+	NopInterceptor
+
+	mu                   sync.Mutex
+	protocol             int32
+	original, translated packet.Packet
+}
+
+// After ...
+func (r *recordingInterceptor) After(ctx *InterceptContext, pk packet.Packet, translated []packet.Packet) []packet.Packet {
+	if ctx.Direction == ToLatest && len(translated) == 1 {
+		if _, ok := translated[0].(*packet.Text); ok {
+			r.mu.Lock()
+			r.protocol, r.original, r.translated = ctx.Protocol, pk, translated[0]
+			r.mu.Unlock()
+		}
+	}
+	return translated
+}
+
+// textInterceptor cancels Text packets with the message "cancel" and appends a suffix to the message of all other
+// Text packets after translation. If inject is true, a Text packet is injected after every Text packet read.
+type textInterceptor struct {
+	suffix string
+	inject bool
+}
+
+// Before ...
+func (textInterceptor) Before(_ *InterceptContext, pk packet.Packet) bool {
+	message, ok := textMessage(pk)
+	return !ok || *message != "cancel"
+}
+
+// After ...
+func (i textInterceptor) After(ctx *InterceptContext, _ packet.Packet, translated []packet.Packet) []packet.Packet {
+	var packets []packet.Packet
+	for _, pk := range translated {
+		packets = append(packets, pk)
+		if message, ok := textMessage(pk); ok {
+			*message += i.suffix
+			if i.inject && ctx.Direction == ToLatest {
+				packets = append(packets, &packet.Text{TextType: packet.TextTypeRaw, Message: "injected"})
+			}
+		}
+	}
+	return packets
+}
+
+// textMessage returns a pointer to the message of a Text packet of either the latest protocol or protocol 671.
+func textMessage(pk packet.Packet) (*string, bool) {
+	switch pk := pk.(type) {
+	case *packet.Text:
+		return &pk.Message, true
+	case *v671packet.Text:
+		return &pk.Message, true
+	}
+	return nil, false
+}
+
+// TestCraftingEvent tests that the CraftingEvent packet of older clients is passed to interceptors, but not to the
+// server.
+func TestCraftingEvent(t *testing.T) {
+	events := make(chan *v622packet.CraftingEvent, 1)
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv622.Protocol{}), WithInterceptors(craftingInterceptor(events))).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, mv622.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+
+	if err := client.WritePacket(&v622packet.CraftingEvent{CraftingType: 1, RecipeUUID: uuid.New()}); err != nil {
+		t.Fatalf("write crafting event: %v", err)
+	}
+	if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: "crafted"}); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+
+	// Packets are only translated when read, so the packets are read before checking for the crafting event.
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			t.Fatalf("read packet: %v", err)
+		}
+		if pk.ID() == v622packet.IDCraftingEvent {
+			t.Fatalf("expected crafting event not to be passed to the server, got %T", pk)
+		}
+		if _, ok := pk.(*packet.Text); ok {
+			break
+		}
+	}
+	select {
+	case ev := <-events:
+		if ev.CraftingType != 1 {
+			t.Errorf("expected crafting type 1, got %v", ev.CraftingType)
+		}
+	default:
+		t.Error("crafting event was not intercepted")
+	}
+}
+
+// craftingInterceptor is an Interceptor that sends every CraftingEvent read from a connection to a channel.
+type craftingInterceptor chan *v622packet.CraftingEvent
+
+// Before ...
+func (craftingInterceptor) Before(*InterceptContext, packet.Packet) bool { return true }
+
+// After ...
+func (c craftingInterceptor) After(ctx *InterceptContext, pk packet.Packet, translated []packet.Packet) []packet.Packet {
+	if ev, ok := pk.(*v622packet.CraftingEvent); ok && ctx.Direction == ToLatest {
+		c <- ev
+	}
+	return translated
+}
@@ -8,18 +8,27 @@ import (
 // listener is a custom minecraft.Listener for multi-version support.
 type listener struct {
 	*minecraft.Listener
+	conns *connections
 }
 
-// Accept accepts an incoming connection.
+// Accept accepts an incoming connection. The connection returned is a *Conn.
 func (l listener) Accept() (session.Conn, error) {
 	conn, err := l.Listener.Accept()
 	if err != nil {
 		return nil, err
 	}
-	return conn.(session.Conn), err
+	return l.conns.add(conn.(*minecraft.Conn)), err
 }
 
 // Disconnect disconnects the connection with the given reason.
 func (l listener) Disconnect(conn session.Conn, reason string) error {
-	return l.Listener.Disconnect(conn.(*minecraft.Conn), reason)
+	c := conn.(*Conn)
+	c.forget()
+	return l.Listener.Disconnect(c.Conn, reason)
+}
+
+// Close closes the listener and all of its connections.
+func (l listener) Close() error {
+	l.conns.close()
+	return l.Listener.Close()
 }
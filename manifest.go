@@ -0,0 +1,312 @@
+package vers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/muhammadmuzzammil1998/jsonc"
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// PackReport holds the changes made to the manifest of a resource pack so that it is accepted by clients of an
+// older version.
+type PackReport struct {
+	// Pack is the original resource pack and Downgraded is the resource pack sent to clients of Version instead.
+	// Downgraded has a different UUID than Pack, so that clients do not confuse it with the original. Encrypted
+	// packs are never downgraded.
+	Pack, Downgraded *resource.Pack
+	// Version is the version of the game that the resource pack was downgraded for, such as "1.20.0".
+	Version string
+	// Stripped holds the JSON paths of the fields of the manifest that were removed or lowered, such as
+	// "header.min_engine_version" or "modules[0].entry". It is empty if the resource pack was only changed to
+	// depend on another downgraded resource pack.
+	Stripped []string
+}
+
+// manifestFormat3 is the first version of the game that accepts resource pack manifests with format_version 3.
+var manifestFormat3 = [3]int{1, 21, 0}
+
+// manifestFormat2Fields holds the fields that a manifest with format_version 2 may have, keyed by the path of the
+// object holding them. Fields not listed are removed from manifests downgraded to format_version 2.
+var manifestFormat2Fields = map[string][]string{
+	"":             {"format_version", "header", "modules", "dependencies", "capabilities", "metadata", "subpacks"},
+	"header":       {"name", "description", "uuid", "version", "min_engine_version", "platform_locked", "lock_template_options", "base_game_version", "allow_random_seed"},
+	"modules":      {"type", "uuid", "version", "description", "entry", "language"},
+	"dependencies": {"uuid", "version", "module_name"},
+	"metadata":     {"authors", "license", "url", "generated_with"},
+	"subpacks":     {"folder_name", "name", "memory_tier"},
+}
+
+// DowngradePacks rewrites the manifests of the resource packs passed so that they are accepted by clients of the
+// version passed, such as "1.20.0". A min_engine_version newer than the version is lowered to it, and manifests
+// with a format_version the client does not support are downgraded to format_version 2, removing all fields that
+// format_version 2 does not have. DowngradePacks returns the resource packs to send to these clients, in which
+// packs that needed no changes are left as they are, together with a report for every pack downgraded.
+// Downgraded packs are archived again and get a new UUID derived from their original UUID and the version, and
+// packs depending on them are changed to depend on the new UUID. Downgraded packs are always sent in-band, even if
+// the original had a download URL. Encrypted packs are never downgraded and are returned as they are: their
+// contents can only be decrypted using the UUID they were encrypted with, and a downgraded copy with the same UUID
+// and version could not be told apart from the original by clients or the listener.
+func DowngradePacks(packs []*resource.Pack, version string) ([]*resource.Pack, []PackReport, error) {
+	target, err := multiversion.ParseVersion(version)
+	if err != nil {
+		return nil, nil, err
+	}
+	manifests := make([]*packManifest, len(packs))
+	for i, pack := range packs {
+		if manifests[i], err = readPackManifest(pack); err != nil {
+			return nil, nil, fmt.Errorf("read manifest of pack %v: %w", pack.Name(), err)
+		}
+		manifests[i].stripped = downgradeManifest(manifests[i].m, target)
+	}
+
+	// Packs depending on a downgraded pack must be changed to depend on its new UUID, which in turn means they
+	// need a new UUID themselves, so this is repeated until no more packs are changed.
+	uuids, changed := map[string]string{}, make([]bool, len(manifests))
+	for repeat := true; repeat; {
+		repeat = false
+		for i, m := range manifests {
+			if changed[i] || m.pack.Encrypted() || (len(m.stripped) == 0 && !m.dependsOn(uuids)) {
+				continue
+			}
+			changed[i], repeat = true, true
+			uuids[m.id()] = uuid.NewSHA1(uuid.NameSpaceURL, []byte(m.id()+"_"+version)).String()
+		}
+	}
+
+	downgraded := make([]*resource.Pack, len(packs))
+	var reports []PackReport
+	for i, m := range manifests {
+		if !changed[i] {
+			downgraded[i] = m.pack
+			continue
+		}
+		if downgraded[i], err = m.rewrite(uuids); err != nil {
+			return nil, nil, fmt.Errorf("downgrade pack %v: %w", m.pack.Name(), err)
+		}
+		reports = append(reports, PackReport{Pack: m.pack, Downgraded: downgraded[i], Version: version, Stripped: m.stripped})
+	}
+	return downgraded, reports, nil
+}
+
+// downgradePacks downgrades the resource packs passed for every protocol passed using DowngradePacks. It returns
+// the resource packs to send to clients of each protocol, ordered by the pack they originate from, so that the
+// order of the resource packs is kept for every protocol.
+func (v *Vers) downgradePacks(packs []*resource.Pack, protocols []minecraft.Protocol) ([]versionPacks, error) {
+	versions := map[string][]*resource.Pack{}
+	for _, proto := range protocols {
+		if _, ok := versions[proto.Ver()]; ok {
+			continue
+		}
+		downgraded, reports, err := DowngradePacks(packs, proto.Ver())
+		if err != nil {
+			return nil, err
+		}
+		versions[proto.Ver()] = downgraded
+		for _, report := range reports {
+			if v.packReport != nil {
+				v.packReport(report)
+			}
+		}
+	}
+	vps := make([]versionPacks, 0, len(packs)*len(protocols))
+	for i := range packs {
+		for _, proto := range protocols {
+			vps = append(vps, versionPacks{min: proto.ID(), max: proto.ID(), packs: versions[proto.Ver()][i : i+1]})
+		}
+	}
+	return vps, nil
+}
+
+// packManifest holds the decoded manifest of a resource pack and the archive it was read from.
+type packManifest struct {
+	pack     *resource.Pack
+	archive  *zip.Reader
+	file     *zip.File
+	m        map[string]any
+	stripped []string
+}
+
+// readPackManifest reads the manifest of the resource pack passed. Like resource.Read, the first file in the
+// archive named manifest.json is used as the manifest.
+func readPackManifest(pack *resource.Pack) (*packManifest, error) {
+	data := make([]byte, pack.Len())
+	if _, err := pack.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	for _, file := range archive.File {
+		if path.Base(file.Name) != "manifest.json" {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open manifest: %w", err)
+		}
+		b, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read manifest: %w", err)
+		}
+		m := map[string]any{}
+		if err := json.Unmarshal(jsonc.ToJSON(b), &m); err != nil {
+			return nil, fmt.Errorf("decode manifest: %w", err)
+		}
+		return &packManifest{pack: pack, archive: archive, file: file, m: m}, nil
+	}
+	return nil, fmt.Errorf("manifest.json not found in archive")
+}
+
+// id returns the UUID of the resource pack of the manifest. UUIDs are compared case-insensitively, so it is
+// always lower case.
+func (m *packManifest) id() string {
+	return strings.ToLower(m.pack.UUID())
+}
+
+// dependsOn checks if the manifest has a dependency on any of the UUIDs passed.
+func (m *packManifest) dependsOn(uuids map[string]string) bool {
+	for _, dep := range objects(m.m["dependencies"]) {
+		if id, ok := dep["uuid"].(string); ok {
+			if _, ok := uuids[strings.ToLower(id)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rewrite returns a resource pack with the contents of the original pack and the downgraded manifest, changing
+// the UUID of the pack and its dependencies to the new UUIDs passed.
+func (m *packManifest) rewrite(uuids map[string]string) (*resource.Pack, error) {
+	if header, ok := m.m["header"].(map[string]any); ok {
+		if newID, ok := uuids[m.id()]; ok {
+			header["uuid"] = newID
+		}
+	}
+	for _, dep := range objects(m.m["dependencies"]) {
+		if id, ok := dep["uuid"].(string); ok {
+			if newID, ok := uuids[strings.ToLower(id)]; ok {
+				dep["uuid"] = newID
+			}
+		}
+	}
+	manifest, err := json.MarshalIndent(m.m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	w := zip.NewWriter(buf)
+	for _, file := range m.archive.File {
+		if file != m.file {
+			if err := w.Copy(file); err != nil {
+				return nil, fmt.Errorf("copy %v: %w", file.Name, err)
+			}
+			continue
+		}
+		f, err := w.Create(file.Name)
+		if err != nil {
+			return nil, fmt.Errorf("create manifest: %w", err)
+		}
+		if _, err := f.Write(manifest); err != nil {
+			return nil, fmt.Errorf("write manifest: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	return resource.Read(buf)
+}
+
+// downgradeManifest downgrades the decoded manifest passed for clients of the target version passed. It returns
+// the paths of all fields that were removed or lowered.
+func downgradeManifest(m map[string]any, target [3]int) []string {
+	var stripped []string
+	if header, ok := m["header"].(map[string]any); ok {
+		if v, ok := versionArray(header["min_engine_version"]); ok && compareVersions(v, target) > 0 {
+			header["min_engine_version"] = []any{target[0], target[1], target[2]}
+			stripped = append(stripped, "header.min_engine_version")
+		}
+	}
+	if format, ok := m["format_version"].(float64); ok && format > 2 && compareVersions(target, manifestFormat3) < 0 {
+		m["format_version"] = 2
+		stripped = append(stripped, "format_version")
+		stripped = append(stripped, stripFields(m, "", "")...)
+		for _, key := range []string{"header", "metadata"} {
+			if obj, ok := m[key].(map[string]any); ok {
+				stripped = append(stripped, stripFields(obj, key, key)...)
+			}
+		}
+		for _, key := range []string{"modules", "dependencies", "subpacks"} {
+			for i, obj := range objects(m[key]) {
+				stripped = append(stripped, stripFields(obj, key, fmt.Sprintf("%v[%v]", key, i))...)
+			}
+		}
+	}
+	return stripped
+}
+
+// stripFields removes all fields from the object passed that a manifest with format_version 2 does not have in
+// the object with the kind passed. It returns the paths of the fields removed, prefixed with the path passed.
+func stripFields(obj map[string]any, kind, p string) []string {
+	var stripped []string
+	for key := range obj {
+		if slices.Contains(manifestFormat2Fields[kind], key) {
+			continue
+		}
+		delete(obj, key)
+		if p == "" {
+			stripped = append(stripped, key)
+		} else {
+			stripped = append(stripped, p+"."+key)
+		}
+	}
+	slices.Sort(stripped)
+	return stripped
+}
+
+// objects returns all JSON objects in the decoded JSON array passed.
+func objects(v any) []map[string]any {
+	arr, _ := v.([]any)
+	objs := make([]map[string]any, 0, len(arr))
+	for _, e := range arr {
+		if obj, ok := e.(map[string]any); ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// versionArray converts a decoded JSON version array, such as [1, 20, 0], to a [3]int.
+func versionArray(v any) (ver [3]int, ok bool) {
+	arr, ok := v.([]any)
+	if !ok || len(arr) != 3 {
+		return ver, false
+	}
+	for i, e := range arr {
+		n, ok := e.(float64)
+		if !ok {
+			return ver, false
+		}
+		ver[i] = int(n)
+	}
+	return ver, true
+}
+
+// compareVersions compares two versions, returning -1 if a is older than b, 1 if a is newer than b and 0 if they
+// are equal.
+func compareVersions(a, b [3]int) int {
+	return slices.Compare(a[:], b[:])
+}
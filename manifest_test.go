@@ -0,0 +1,204 @@
+package vers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// TestDowngradePacks tests that manifests are downgraded for older versions, that packs depending on downgraded
+// packs are changed to depend on the new pack, regardless of the case of its UUID, and that packs that need no
+// changes are kept.
+func TestDowngradePacks(t *testing.T) {
+	textures, scripts, plain := uuid.NewString(), uuid.NewString(), testPack(t, "plain")
+	packs := []*resource.Pack{
+		testPackManifest(t, fmt.Sprintf(`{
+	// Manifests may hold comments.
+	"format_version": 3,
+	"header": {"name": "textures", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0], "pack_scope": "world"},
+	"modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}],
+	"settings": []
+}`, strings.ToUpper(textures), uuid.New())),
+		testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": "scripts", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 20, 0]},
+	"modules": [{"type": "data", "uuid": %q, "version": [1, 0, 0]}],
+	"dependencies": [{"uuid": %q, "version": [1, 0, 0]}]
+}`, scripts, uuid.New(), strings.ToUpper(textures))),
+		plain,
+	}
+
+	downgraded, reports, err := DowngradePacks(packs, mv589.Protocol{}.Ver())
+	if err != nil {
+		t.Fatalf("downgrade packs: %v", err)
+	}
+	if len(downgraded) != 3 || downgraded[2] != plain {
+		t.Fatalf("expected pack without changes to be kept, got %v", downgraded)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %v", len(reports))
+	}
+	if want := []string{"header.min_engine_version", "format_version", "settings", "header.pack_scope"}; !slices.Equal(reports[0].Stripped, want) {
+		t.Errorf("expected %v to be stripped, got %v", want, reports[0].Stripped)
+	}
+	if len(reports[1].Stripped) != 0 {
+		t.Errorf("expected nothing to be stripped from dependent pack, got %v", reports[1].Stripped)
+	}
+
+	manifest := downgraded[0].Manifest()
+	if manifest.FormatVersion != 2 || manifest.Header.MinimumGameVersion != [3]int{1, 20, 0} {
+		t.Errorf("expected manifest to be downgraded, got %+v", manifest)
+	}
+	if strings.EqualFold(downgraded[0].UUID(), textures) || downgraded[1].UUID() == scripts {
+		t.Errorf("expected downgraded packs to get a new UUID")
+	}
+	if deps := downgraded[1].Manifest().Dependencies; len(deps) != 1 || deps[0].UUID != downgraded[0].UUID() {
+		t.Errorf("expected dependency on %v, got %+v", downgraded[0].UUID(), deps)
+	}
+
+	if _, reports, err := DowngradePacks(packs, minecraft.DefaultProtocol.Ver()); err != nil || len(reports) != 0 {
+		t.Errorf("expected no packs to be downgraded for the latest version, got %v, %v", reports, err)
+	}
+}
+
+// TestDowngradeEncryptedPacks tests that encrypted packs are never downgraded, as a copy would share their UUID
+// and version, and that packs depending on them are left as they are.
+func TestDowngradeEncryptedPacks(t *testing.T) {
+	id := uuid.NewString()
+	pack := encryptedPack(t, id)
+	dependent := testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": "dependent", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 20, 0]},
+	"modules": [{"type": "data", "uuid": %q, "version": [1, 0, 0]}],
+	"dependencies": [{"uuid": %q, "version": [1, 0, 0]}]
+}`, uuid.New(), uuid.New(), id))
+
+	downgraded, reports, err := DowngradePacks([]*resource.Pack{pack, dependent}, mv589.Protocol{}.Ver())
+	if err != nil {
+		t.Fatalf("downgrade packs: %v", err)
+	}
+	if len(reports) != 0 || downgraded[0] != pack || downgraded[1] != dependent {
+		t.Fatalf("expected no packs to be downgraded, got %v", reports)
+	}
+}
+
+// TestPackDowngrading tests that clients are sent resource packs downgraded for their version, while clients on
+// the latest version are sent the original resource packs.
+func TestPackDowngrading(t *testing.T) {
+	pack := testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": "pack", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]},
+	"modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]
+}`, uuid.New(), uuid.New()))
+
+	var reports []PackReport
+	conf := newConfig()
+	conf.Resources = []*resource.Pack{pack}
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv589.Protocol{}), WithPackDowngrading(func(r PackReport) {
+		reports = append(reports, r)
+	})).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	if len(reports) != 1 || reports[0].Version != (mv589.Protocol{}).Ver() {
+		t.Fatalf("expected pack to be downgraded for %v only, got %+v", mv589.Protocol{}.Ver(), reports)
+	}
+
+	for _, tc := range []struct {
+		proto minecraft.Protocol
+		want  *resource.Pack
+	}{
+		{mv589.Protocol{}, reports[0].Downgraded},
+		{minecraft.DefaultProtocol, pack},
+	} {
+		t.Run(tc.proto.Ver(), func(t *testing.T) {
+			client, conn, err := join(l, tc.proto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			defer conn.Close()
+
+			if packs := client.ResourcePacks(); len(packs) != 1 || packs[0].UUID() != tc.want.UUID() {
+				t.Errorf("expected resource pack %v, got %v", tc.want.UUID(), packs)
+			}
+		})
+	}
+}
+
+// TestPackDowngradingEncrypted tests that clients of every version are sent an encrypted pack as it is, next to the
+// downgraded copy of an unencrypted pack for older clients.
+func TestPackDowngradingEncrypted(t *testing.T) {
+	encrypted := encryptedPack(t, uuid.NewString())
+	plain := testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": "plain", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]},
+	"modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]
+}`, uuid.New(), uuid.New()))
+
+	var reports []PackReport
+	conf := newConfig()
+	conf.Resources = []*resource.Pack{encrypted, plain}
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv589.Protocol{}), WithPackDowngrading(func(r PackReport) {
+		reports = append(reports, r)
+	})).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	if len(reports) != 1 || reports[0].Pack != plain {
+		t.Fatalf("expected only the unencrypted pack to be downgraded, got %+v", reports)
+	}
+
+	for _, tc := range []struct {
+		proto minecraft.Protocol
+		want  []*resource.Pack
+	}{
+		{mv589.Protocol{}, []*resource.Pack{encrypted, reports[0].Downgraded}},
+		{minecraft.DefaultProtocol, []*resource.Pack{encrypted, plain}},
+	} {
+		t.Run(tc.proto.Ver(), func(t *testing.T) {
+			client, conn, err := join(l, tc.proto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			defer conn.Close()
+
+			// Packs are compared by their UUID and min_engine_version, as a downgraded copy of the encrypted pack
+			// would have the same UUID.
+			var got, want []string
+			for _, pack := range client.ResourcePacks() {
+				got = append(got, fmt.Sprint(pack.UUID(), pack.Manifest().Header.MinimumGameVersion))
+			}
+			for _, pack := range tc.want {
+				want = append(want, fmt.Sprint(pack.UUID(), pack.Manifest().Header.MinimumGameVersion))
+			}
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("expected resource packs %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+// encryptedPack returns an encrypted resource pack with the UUID passed and a min_engine_version that is too new
+// for older clients.
+func encryptedPack(t *testing.T, id string) *resource.Pack {
+	return testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": "encrypted", "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 21, 0]},
+	"modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]
+}`, id, uuid.New())).WithContentKey("0123456789abcdef0123456789abcdef")
+}
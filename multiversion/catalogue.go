@@ -0,0 +1,117 @@
+// Package multiversion holds a catalogue of all protocols implemented by the packages in its subdirectories,
+// so that protocols may be enumerated, looked up and selected by version without importing each package.
+package multiversion
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/multiversion/mv594"
+	"github.com/oomph-ac/mv/multiversion/mv618"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/oomph-ac/mv/multiversion/mv630"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/multiversion/mv662"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// protocols holds all built-in protocols in the order they were released in, ending with the latest protocol.
+var protocols = []minecraft.Protocol{
+	mv589.Protocol{},
+	mv594.Protocol{},
+	mv618.Protocol{},
+	mv622.Protocol{},
+	mv630.Protocol{},
+	mv649.Protocol{},
+	mv662.Protocol{},
+	mv671.Protocol{},
+	minecraft.DefaultProtocol,
+}
+
+// Protocols returns all built-in protocols in the order they were released in, from oldest to newest. The last
+// protocol returned is always minecraft.DefaultProtocol, the latest protocol.
+func Protocols() []minecraft.Protocol {
+	return slices.Clone(protocols)
+}
+
+// Legacy returns all built-in protocols except for the latest protocol, from oldest to newest. These are the
+// protocols that may be passed to vers.WithProtocols to accept every supported version:
+//
+//	vers.New(":19132", vers.WithProtocols(multiversion.Legacy()...))
+func Legacy() []minecraft.Protocol {
+	return slices.Clone(protocols[:len(protocols)-1])
+}
+
+// ByID returns the built-in protocol with the protocol ID passed, such as 589 for mv589.Protocol{}. False is
+// returned if no built-in protocol has the ID.
+func ByID(id int32) (minecraft.Protocol, bool) {
+	for _, proto := range protocols {
+		if proto.ID() == id {
+			return proto, true
+		}
+	}
+	return nil, false
+}
+
+// ByVersion returns the built-in protocol with the version passed, such as "1.20.0" for mv589.Protocol{}. False
+// is returned if no built-in protocol has the version.
+func ByVersion(ver string) (minecraft.Protocol, bool) {
+	for _, proto := range protocols {
+		if proto.Ver() == ver {
+			return proto, true
+		}
+	}
+	return nil, false
+}
+
+// Range returns all built-in protocols with a version between from and to, inclusive, from oldest to newest. The
+// versions passed need not be versions of built-in protocols: Range("1.20.0", "1.20.45") returns the protocols
+// of 1.20.0 through 1.20.40. An error is returned if from or to is not a valid version.
+func Range(from, to string) ([]minecraft.Protocol, error) {
+	min, err := ParseVersion(from)
+	if err != nil {
+		return nil, err
+	}
+	max, err := ParseVersion(to)
+	if err != nil {
+		return nil, err
+	}
+	var selected []minecraft.Protocol
+	for _, proto := range protocols {
+		v, err := ParseVersion(proto.Ver())
+		if err != nil {
+			return nil, fmt.Errorf("protocol %v: %w", proto.ID(), err)
+		}
+		if slices.Compare(v[:], min[:]) >= 0 && slices.Compare(v[:], max[:]) <= 0 {
+			selected = append(selected, proto)
+		}
+	}
+	return selected, nil
+}
+
+// From returns all built-in protocols with a version of at least the version passed, from oldest to newest and
+// including the latest protocol. From("1.20.0") returns all protocols from 1.20.0 through the latest version.
+// An error is returned if the version is not valid.
+func From(ver string) ([]minecraft.Protocol, error) {
+	return Range(ver, minecraft.DefaultProtocol.Ver())
+}
+
+// ParseVersion parses a version of the game, such as "1.20.0", into its major, minor and patch parts. Any parts
+// after the third, such as the build number in "1.20.0.1", are ignored. Versions may be compared by comparing
+// their parts in order, for example using slices.Compare.
+func ParseVersion(ver string) (parts [3]int, err error) {
+	s := strings.Split(ver, ".")
+	if len(s) < 3 {
+		return parts, fmt.Errorf("invalid version %q", ver)
+	}
+	for i := range parts {
+		if parts[i], err = strconv.Atoi(s[i]); err != nil {
+			return parts, fmt.Errorf("invalid version %q: %w", ver, err)
+		}
+	}
+	return parts, nil
+}
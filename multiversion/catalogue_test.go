@@ -0,0 +1,53 @@
+package multiversion_test
+
+import (
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/oomph-ac/mv/multiversion/mv662"
+	"github.com/sandertv/gophertunnel/minecraft"
+)
+
+// TestCatalogue tests that the catalogue holds all protocols in release order, and that protocols may be looked
+// up and selected by their ID and version.
+func TestCatalogue(t *testing.T) {
+	all := multiversion.Protocols()
+	if all[0] != (mv589.Protocol{}) || all[len(all)-1] != minecraft.DefaultProtocol {
+		t.Fatalf("expected protocols from 1.20.0 through the latest version, got %v", all)
+	}
+	for i, proto := range all {
+		if i > 0 && proto.ID() <= all[i-1].ID() {
+			t.Errorf("expected protocol %v to be released after %v", proto.Ver(), all[i-1].Ver())
+		}
+		if p, ok := multiversion.ByID(proto.ID()); !ok || p != proto {
+			t.Errorf("expected lookup of ID %v to return %v, got %v", proto.ID(), proto.Ver(), p)
+		}
+		if p, ok := multiversion.ByVersion(proto.Ver()); !ok || p != proto {
+			t.Errorf("expected lookup of version %v to return %v, got %v", proto.Ver(), proto.Ver(), p)
+		}
+	}
+	if legacy := multiversion.Legacy(); len(legacy) != len(all)-1 {
+		t.Errorf("expected all but the latest protocol, got %v", legacy)
+	}
+	if _, ok := multiversion.ByID(1); ok {
+		t.Errorf("expected no protocol with ID 1")
+	}
+
+	if r, err := multiversion.Range("1.20.0", "1.20.45"); err != nil || len(r) != 4 || r[3] != (mv622.Protocol{}) {
+		t.Errorf("expected protocols 1.20.0 through 1.20.40, got %v (%v)", r, err)
+	}
+	if r, err := multiversion.From("1.20.70"); err != nil || len(r) != 3 || r[0] != (mv662.Protocol{}) || r[2] != minecraft.DefaultProtocol {
+		t.Errorf("expected protocols 1.20.70 through the latest version, got %v (%v)", r, err)
+	}
+	if r, err := multiversion.From("1.20.0"); err != nil || len(r) != len(all) {
+		t.Errorf("expected all protocols, got %v (%v)", r, err)
+	}
+	if _, err := multiversion.Range("1.20", "latest"); err == nil {
+		t.Errorf("expected Range to return an error for an invalid version")
+	}
+	if _, err := multiversion.From("latest"); err == nil {
+		t.Errorf("expected From to return an error for an invalid version")
+	}
+}
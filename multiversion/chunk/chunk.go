@@ -113,3 +113,12 @@ func (chunk *Chunk) subIndex(y int16) int16 {
 func (chunk *Chunk) subY(index int16) int16 {
 	return (index << 4) + int16(chunk.r[0])
 }
+
+// oldFormatOffset returns the index of the sub chunk at Y=0 in a chunk with the range passed. Chunks in the old
+// format used before 1.18 start at Y=0, so their first sub chunk is at this index.
+func oldFormatOffset(r cube.Range) int {
+	if r[0] >= 0 {
+		return 0
+	}
+	return -r[0] >> 4
+}
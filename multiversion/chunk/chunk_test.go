@@ -0,0 +1,397 @@
+package chunk_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/world"
+	dfchunk "github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/oomph-ac/mv/multiversion/chunk"
+	"github.com/oomph-ac/mv/multiversion/latest"
+	"github.com/oomph-ac/mv/multiversion/mappings"
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/multiversion/mv594"
+	"github.com/oomph-ac/mv/multiversion/mv618"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/oomph-ac/mv/multiversion/mv630"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/multiversion/mv662"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/multiversion/util"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// versions holds the block mappings of every supported version, keyed by their protocol ID.
+var versions = []struct {
+	proto   minecraft.Protocol
+	mapping mappings.MVMapping
+}{
+	{mv589.Protocol{}, mv589.Mapping},
+	{mv594.Protocol{}, mv594.Mapping},
+	{mv618.Protocol{}, mv618.Mapping},
+	{mv622.Protocol{}, mv622.Mapping},
+	{mv630.Protocol{}, mv630.Mapping},
+	{mv649.Protocol{}, mv649.Mapping},
+	{mv662.Protocol{}, mv662.Mapping},
+	{mv671.Protocol{}, mv671.Mapping},
+}
+
+// r is the range of the chunks used in the tests.
+var r = world.Overworld.Range()
+
+// TestGolden decodes the golden chunk of the latest version and every supported version in the testdata directory
+// and checks that encoding the decoded chunk again results in exactly the same bytes.
+func TestGolden(t *testing.T) {
+	check := func(t *testing.T, name string, airRID uint32) {
+		want := readGolden(t, name)
+		c, err := chunk.NetworkDecode(airRID, bytes.NewBuffer(want), len(sampleChunk().Sub()), false, r)
+		if err != nil {
+			t.Fatalf("decode golden chunk: %v", err)
+		}
+		if got, _ := encode(c); !bytes.Equal(want, got) {
+			t.Errorf("re-encoded chunk differs from golden file %v", name)
+		}
+	}
+	t.Run("latest", func(t *testing.T) {
+		check(t, "level_chunk_latest.bin", latest.AirRuntimeID())
+	})
+	for _, v := range versions {
+		t.Run(v.proto.Ver(), func(t *testing.T) {
+			check(t, golden(v.proto), v.mapping.LegacyAirRID)
+		})
+	}
+}
+
+// TestGoldenDowngrade downgrades the golden chunk of the latest version to every supported version and checks that
+// the result is byte for byte equal to the golden chunk of that version.
+func TestGoldenDowngrade(t *testing.T) {
+	payload := readGolden(t, "level_chunk_latest.bin")
+	for _, v := range versions {
+		t.Run(v.proto.Ver(), func(t *testing.T) {
+			pk := &packet.LevelChunk{SubChunkCount: uint32(len(sampleChunk().Sub())), RawPayload: payload}
+			util.DefaultDowngrade(new(minecraft.Conn), pk, v.mapping)
+			if !bytes.Equal(readGolden(t, golden(v.proto)), pk.RawPayload) {
+				t.Errorf("downgraded chunk differs from golden file %v", golden(v.proto))
+			}
+		})
+	}
+}
+
+// TestDowngrade downgrades a chunk encoded by Dragonfly to every supported version and decodes the result using
+// the chunk decoder of Dragonfly, so that the chunks sent are checked against an implementation of the format
+// other than the one under test.
+func TestDowngrade(t *testing.T) {
+	want := sampleChunk()
+	payload, count := dragonflyPayload(want, func(rid uint32) uint32 { return rid })
+	for _, v := range versions {
+		t.Run(v.proto.Ver(), func(t *testing.T) {
+			pk := &packet.LevelChunk{SubChunkCount: uint32(count), RawPayload: payload}
+			util.DefaultDowngrade(new(minecraft.Conn), pk, v.mapping)
+
+			got, err := dfchunk.NetworkDecode(v.mapping.LegacyAirRID, pk.RawPayload, int(pk.SubChunkCount), r)
+			if err != nil {
+				t.Fatalf("decode downgraded chunk: %v", err)
+			}
+			downgraded := func(x uint8, y int16, z uint8, layer uint8) uint32 {
+				return util.DowngradeBlockRuntimeID(want.Block(x, y, z, layer), v.mapping)
+			}
+			if err := compareBlocks(downgraded, got.Block); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestUpgrade upgrades a chunk of every supported version encoded by Dragonfly to the latest version and checks
+// if all blocks in it are equal to those in the sample chunk it was produced from.
+func TestUpgrade(t *testing.T) {
+	want := sampleChunk()
+	for _, v := range versions {
+		t.Run(v.proto.Ver(), func(t *testing.T) {
+			payload, count := dragonflyPayload(want, func(rid uint32) uint32 { return util.DowngradeBlockRuntimeID(rid, v.mapping) })
+			pk := &packet.LevelChunk{SubChunkCount: uint32(count), RawPayload: payload}
+			util.DefaultUpgrade(new(minecraft.Conn), pk, v.mapping)
+
+			got, err := dfchunk.NetworkDecode(latest.AirRuntimeID(), pk.RawPayload, int(pk.SubChunkCount), r)
+			if err != nil {
+				t.Fatalf("decode upgraded chunk: %v", err)
+			}
+			if err := compareBlocks(want.Block, got.Block); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestOldFormat downgrades a chunk for a mapping using the chunk format of versions before 1.18 and checks that
+// it is encoded in that format, and that upgrading it again results in the same blocks and biomes.
+func TestOldFormat(t *testing.T) {
+	mapping := mappings.Mapping(latestBlockStates(), latest.ItemRuntimeIDData, true)
+	blocks := sampleBlocks(16)
+	want := chunk.New(latest.AirRuntimeID(), r)
+	for y := int16(0); y < 64; y++ {
+		for x := uint8(0); x < 16; x++ {
+			for z := uint8(0); z < 16; z++ {
+				want.SetBlock(x, y, z, 0, blocks[(int(x)+int(y)*3+int(z)*7)%len(blocks)])
+				want.SetBiome(x, want.HighestBlock(x, z), z, uint32(x+z))
+			}
+		}
+	}
+	payload, count := encode(want)
+
+	pk := &packet.LevelChunk{SubChunkCount: uint32(count), RawPayload: payload}
+	util.DefaultDowngrade(new(minecraft.Conn), pk, mapping)
+	if pk.SubChunkCount != 16 {
+		t.Fatalf("expected 16 sub chunks in old format, got %v", pk.SubChunkCount)
+	}
+	if pk.RawPayload[0] != chunk.OldSubChunkVersion {
+		t.Fatalf("expected sub chunk version %v, got %v", chunk.OldSubChunkVersion, pk.RawPayload[0])
+	}
+	buf := bytes.NewBuffer(pk.RawPayload)
+	old, err := chunk.NetworkDecode(mapping.LegacyAirRID, buf, int(pk.SubChunkCount), true, r)
+	if err != nil {
+		t.Fatalf("decode old format chunk: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("%v unread bytes left after decoding old format chunk", buf.Len())
+	}
+	if err := compareBlocks(want.Block, old.Block); err != nil {
+		t.Error(err)
+	}
+
+	util.DefaultUpgrade(new(minecraft.Conn), pk, mapping)
+	got, err := chunk.NetworkDecode(latest.AirRuntimeID(), bytes.NewBuffer(pk.RawPayload), int(pk.SubChunkCount), false, r)
+	if err != nil {
+		t.Fatalf("decode upgraded chunk: %v", err)
+	}
+	if err := compareBlocks(want.Block, got.Block); err != nil {
+		t.Error(err)
+	}
+	for x := uint8(0); x < 16; x++ {
+		for z := uint8(0); z < 16; z++ {
+			if b := got.Biome(x, got.HighestBlock(x, z), z); b != uint32(x+z) {
+				t.Fatalf("biome at (%v, %v): expected %v, got %v", x, z, x+z, b)
+			}
+		}
+	}
+}
+
+// TestNetworkDecodeMalformed checks that malformed chunks result in an error being returned by NetworkDecode.
+func TestNetworkDecodeMalformed(t *testing.T) {
+	valid, count := encode(sampleChunk())
+	for name, data := range map[string]struct {
+		payload   []byte
+		count     int
+		oldFormat bool
+	}{
+		"Empty":                  {payload: nil, count: count},
+		"Truncated":              {payload: valid[:len(valid)/2], count: count},
+		"MissingBiomes":          {payload: valid[:len(valid)-1], count: count},
+		"TooManySubChunks":       {payload: valid, count: count + 1},
+		"TooManyOldSubChunks":    {payload: valid, count: count - 3, oldFormat: true},
+		"UnknownVersion":         {payload: []byte{2}, count: 1},
+		"SubChunkIndex":          {payload: []byte{9, 0, 100}, count: 1},
+		"StorageSize":            {payload: []byte{8, 1, 7 << 1}, count: 1},
+		"StoragePointsBack":      {payload: []byte{8, 1, 0x7f<<1 | 1}, count: 1},
+		"PaletteCount":           {payload: append(append([]byte{8, 1, 1<<1 | 1}, make([]byte, 512)...), 0xff, 0xff, 0xff, 0xff, 0x07), count: 1},
+		"PersistentPaletteCount": {payload: append([]byte{8, 1, 1 << 1}, make([]byte, 512)...), count: 1},
+		"OldBiomes":              {payload: []byte{8, 0}, count: 1, oldFormat: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := chunk.NetworkDecode(latest.AirRuntimeID(), bytes.NewBuffer(data.payload), data.count, data.oldFormat, r); err == nil {
+				t.Error("expected error decoding malformed chunk")
+			}
+		})
+	}
+}
+
+// TestPaletteIndexOutOfRange checks that indices of a decoded storage that point past the end of its palette
+// resolve to the first value of the palette, instead of panicking when the storage is read.
+func TestPaletteIndexOutOfRange(t *testing.T) {
+	// A storage of 1 bit per block, with every index set to 1, and a palette holding a single value.
+	payload := append(append([]byte{8, 1, 1<<1 | 1}, bytes.Repeat([]byte{0xff}, 512)...), 2, 4)
+	var index byte
+	sub, err := chunk.DecodeSubChunk(latest.AirRuntimeID(), r, bytes.NewBuffer(payload), &index, chunk.NetworkEncoding)
+	if err != nil {
+		t.Fatalf("decode sub chunk: %v", err)
+	}
+	if v := sub.Layer(0).At(1, 2, 3); v != 2 {
+		t.Errorf("expected first palette value 2, got %v", v)
+	}
+}
+
+// FuzzNetworkDecode checks that decoding arbitrary chunk data never panics, and that chunks that are decoded
+// successfully may be encoded and decoded again.
+func FuzzNetworkDecode(f *testing.F) {
+	payload, count := encode(sampleChunk())
+	f.Add(payload, uint8(count), false)
+	for _, v := range versions {
+		b, n := dragonflyPayload(sampleChunk(), func(rid uint32) uint32 { return util.DowngradeBlockRuntimeID(rid, v.mapping) })
+		f.Add(b, uint8(n), false)
+	}
+	f.Add([]byte{8, 0}, uint8(1), true)
+
+	f.Fuzz(func(t *testing.T, payload []byte, count uint8, oldFormat bool) {
+		c, err := chunk.NetworkDecode(latest.AirRuntimeID(), bytes.NewBuffer(payload), int(count), oldFormat, r)
+		if err != nil {
+			return
+		}
+		encoded, n := encode(c)
+		if _, err := chunk.NetworkDecode(latest.AirRuntimeID(), bytes.NewBuffer(encoded), n, false, r); err != nil {
+			t.Fatalf("decode re-encoded chunk: %v", err)
+		}
+	})
+}
+
+// FuzzDecodeSubChunk checks that decoding arbitrary sub chunk data never panics.
+func FuzzDecodeSubChunk(f *testing.F) {
+	for _, sub := range sampleChunk().Sub()[:4] {
+		f.Add(chunk.EncodeSubChunk(sub, chunk.NetworkEncoding, r, 0))
+	}
+	f.Add([]byte{1, 0, 0})
+	f.Add([]byte{8, 1, 0})
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var index byte
+		sub, err := chunk.DecodeSubChunk(latest.AirRuntimeID(), r, bytes.NewBuffer(payload), &index, chunk.NetworkEncoding)
+		if err != nil {
+			return
+		}
+		for _, layer := range sub.Layers() {
+			for x := byte(0); x < 16; x++ {
+				for y := byte(0); y < 16; y++ {
+					for z := byte(0); z < 16; z++ {
+						layer.At(x, y, z)
+					}
+				}
+			}
+		}
+	})
+}
+
+// sampleChunk returns a chunk of the latest version filled with a pattern of blocks that exist in every supported
+// version, so that the chunk survives a downgrade and upgrade without loss.
+func sampleChunk() *chunk.Chunk {
+	blocks := sampleBlocks(16)
+	water, _ := latest.StateToRuntimeID("minecraft:water", map[string]any{"liquid_depth": int32(0)})
+
+	c := chunk.New(latest.AirRuntimeID(), r)
+	for i := 0; i < 4; i++ {
+		for x := uint8(0); x < 16; x++ {
+			for z := uint8(0); z < 16; z++ {
+				for y := uint8(0); y < 16; y++ {
+					c.SetBlock(x, int16(r.Min()+i<<4)+int16(y), z, 0, blocks[(int(x)+int(y)*3+int(z)*7+i)%len(blocks)])
+				}
+			}
+		}
+		c.SetBlock(uint8(i), int16(r.Min()+i<<4), 0, 1, water)
+	}
+	return c
+}
+
+// sampleBlocks returns n runtime IDs of blocks of the latest version that are translated back to the same block
+// after a downgrade and upgrade for every supported version.
+func sampleBlocks(n int) []uint32 {
+	var blocks []uint32
+	for rid := uint32(1); len(blocks) < n; rid += 101 {
+		if _, _, ok := latest.RuntimeIDToState(rid); !ok {
+			panic("not enough sample blocks")
+		}
+		supported := rid != latest.AirRuntimeID()
+		for _, v := range versions {
+			if util.UpgradeBlockRuntimeID(util.DowngradeBlockRuntimeID(rid, v.mapping), v.mapping) != rid {
+				supported = false
+			}
+		}
+		if supported {
+			blocks = append(blocks, rid)
+		}
+	}
+	return blocks
+}
+
+// latestBlockStates encodes all block states of the latest version in the format of the block_states.nbt files
+// embedded in the mappings of every version, so that a mapping of the latest version may be created.
+func latestBlockStates() []byte {
+	buf := bytes.NewBuffer(nil)
+	enc := nbt.NewEncoder(buf)
+	for rid := uint32(0); ; rid++ {
+		name, properties, ok := latest.RuntimeIDToState(rid)
+		if !ok {
+			return buf.Bytes()
+		}
+		_ = enc.Encode(latest.State{Name: name, Properties: properties, Version: dfchunk.CurrentBlockVersion})
+	}
+}
+
+// encode encodes a chunk to its network representation, as sent in a LevelChunk packet, and returns it together
+// with the amount of sub chunks in the payload.
+func encode(c *chunk.Chunk) ([]byte, int) {
+	data := chunk.Encode(c, chunk.NetworkEncoding, r)
+	buf := bytes.NewBuffer(nil)
+	for _, sub := range data.SubChunks {
+		buf.Write(sub)
+	}
+	buf.Write(data.Biomes)
+	return buf.Bytes(), len(data.SubChunks)
+}
+
+// dragonflyPayload creates a chunk of Dragonfly holding the blocks of the chunk passed, converted using the
+// function passed, and encodes it in the same way as Dragonfly does in LevelChunk packets. It returns the payload
+// together with the amount of sub chunks in it.
+func dragonflyPayload(c *chunk.Chunk, convert func(rid uint32) uint32) ([]byte, int) {
+	df := dfchunk.New(convert(latest.AirRuntimeID()), r)
+	for y := int16(r.Min()); y <= int16(r.Max()); y++ {
+		for x := uint8(0); x < 16; x++ {
+			for z := uint8(0); z < 16; z++ {
+				for layer := uint8(0); layer < 2; layer++ {
+					if rid := c.Block(x, y, z, layer); rid != latest.AirRuntimeID() {
+						df.SetBlock(x, y, z, layer, convert(rid))
+					}
+				}
+			}
+		}
+	}
+	data := dfchunk.Encode(df, dfchunk.NetworkEncoding)
+	buf := bytes.NewBuffer(nil)
+	for _, sub := range data.SubChunks {
+		buf.Write(sub)
+	}
+	buf.Write(data.Biomes)
+	return buf.Bytes(), len(data.SubChunks)
+}
+
+// compareBlocks compares all blocks returned by two functions, returning an error for the first block that differs.
+func compareBlocks(want, got func(x uint8, y int16, z uint8, layer uint8) uint32) error {
+	for y := int16(r.Min()); y <= int16(r.Max()); y++ {
+		for x := uint8(0); x < 16; x++ {
+			for z := uint8(0); z < 16; z++ {
+				for layer := uint8(0); layer < 2; layer++ {
+					if a, b := want(x, y, z, layer), got(x, y, z, layer); a != b {
+						return fmt.Errorf("block at (%v, %v, %v) layer %v: expected %v, got %v", x, y, z, layer, a, b)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// golden returns the name of the golden file of the protocol passed.
+func golden(proto minecraft.Protocol) string {
+	return fmt.Sprintf("level_chunk_%v.bin", proto.ID())
+}
+
+// readGolden reads the golden file with the name passed from the testdata directory.
+func readGolden(t *testing.T, name string) []byte {
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	return b
+}
@@ -8,28 +8,34 @@ import (
 
 // NetworkDecode decodes the network serialised data passed into a Chunk if successful. If not, the chunk
 // returned is nil and the error non-nil.
-// The sub chunk count passed must be that found in the LevelChunk packet.
+// The sub chunk count passed must be that found in the LevelChunk packet. If oldFormat is true, the data is
+// decoded in the format used before 1.18, in which the first sub chunk is at Y=0 and biomes are 2D.
 // noinspection GoUnusedExportedFunction
 func NetworkDecode(air uint32, buf *bytes.Buffer, count int, oldFormat bool, r cube.Range) (*Chunk, error) {
-	var (
-		c   = New(air, r)
-		err error
-	)
+	c := New(air, r)
+	offset := 0
+	if oldFormat {
+		offset = oldFormatOffset(r)
+	}
+	if count < 0 || count+offset > len(c.sub) {
+		return nil, fmt.Errorf("invalid sub chunk count %v: chunk has %v sub chunks", count, len(c.sub)-offset)
+	}
 	for i := 0; i < count; i++ {
-		index := uint8(i)
-		if oldFormat {
-			index += 4
-		}
-		c.sub[index], err = DecodeSubChunk(air, r, buf, &index, NetworkEncoding)
+		index := uint8(i + offset)
+		sub, err := DecodeSubChunk(air, r, buf, &index, NetworkEncoding)
 		if err != nil {
 			return nil, err
 		}
+		if int(index) >= len(c.sub) {
+			return nil, fmt.Errorf("invalid sub chunk index %v: chunk has %v sub chunks", index, len(c.sub))
+		}
+		c.sub[index] = sub
 	}
 	if oldFormat {
 		// Read the old biomes.
-		biomes := make([]byte, 256)
-		if _, err := buf.Read(biomes); err != nil {
-			return nil, fmt.Errorf("error reading biomes: %w", err)
+		biomes := buf.Next(256)
+		if len(biomes) != 256 {
+			return nil, fmt.Errorf("error reading biomes: expected 256 bytes, got %v", len(biomes))
 		}
 
 		// Make our 2D biomes 3D.
@@ -84,6 +90,9 @@ func DecodeSubChunk(air uint32, r cube.Range, buf *bytes.Buffer, index *byte, e
 		if err != nil {
 			return nil, err
 		}
+		if storage == nil {
+			return nil, fmt.Errorf("block storage pointed to previous one")
+		}
 		sub.storages = append(sub.storages, storage)
 	case 8, 9:
 		// Version 8 allows up to 256 layers for one sub chunk.
@@ -107,6 +116,10 @@ func DecodeSubChunk(air uint32, r cube.Range, buf *bytes.Buffer, index *byte, e
 			if err != nil {
 				return nil, err
 			}
+			if sub.storages[i] == nil {
+				// Only biome storages may point to the previous storage.
+				return nil, fmt.Errorf("block storage %v pointed to previous one", i)
+			}
 		}
 	}
 	return sub, nil
@@ -129,19 +142,24 @@ func decodePalettedStorage(buf *bytes.Buffer, e Encoding, pe paletteEncoding) (*
 	}
 
 	size := paletteSize(blockSize)
+	if !size.valid() {
+		return nil, fmt.Errorf("invalid paletted storage size %v", blockSize)
+	}
 	uint32Count := size.uint32s()
-
-	uint32s := make([]uint32, uint32Count)
 	byteCount := uint32Count * 4
 
 	data := buf.Next(byteCount)
 	if len(data) != byteCount {
 		return nil, fmt.Errorf("cannot read paletted storage (size=%v) %T: not enough block data present: expected %v bytes, got %v", blockSize, pe, byteCount, len(data))
 	}
+	uint32s := make([]uint32, uint32Count)
 	for i := 0; i < uint32Count; i++ {
 		// Explicitly don't use the binary package to greatly improve performance of reading the uint32s.
 		uint32s[i] = uint32(data[i*4]) | uint32(data[i*4+1])<<8 | uint32(data[i*4+2])<<16 | uint32(data[i*4+3])<<24
 	}
-	p, err := e.decodePalette(buf, paletteSize(blockSize), pe)
-	return newPalettedStorage(uint32s, p), err
+	p, err := e.decodePalette(buf, size, pe)
+	if err != nil {
+		return nil, err
+	}
+	return newPalettedStorage(uint32s, p), nil
 }
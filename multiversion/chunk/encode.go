@@ -46,16 +46,40 @@ func Encode(c *Chunk, e Encoding, r cube.Range) SerialisedData {
 	return d
 }
 
+// EncodeOldFormat encodes Chunk to an intermediate representation SerialisedData in the format used before 1.18.
+// Only the 16 sub chunks from Y=0 upwards are encoded, without their Y index, and the biomes are encoded as a
+// single 2D biome array, taking the biome of the highest block in every column.
+func EncodeOldFormat(c *Chunk, e Encoding, r cube.Range) SerialisedData {
+	offset := oldFormatOffset(r)
+	end := min(offset+16, len(c.sub))
+	d := SerialisedData{SubChunks: make([][]byte, 0, end-offset)}
+	for i := offset; i < end; i++ {
+		d.SubChunks = append(d.SubChunks, encodeSubChunk(c.sub[i], e, []byte{OldSubChunkVersion, byte(len(c.sub[i].storages))}))
+	}
+	d.Biomes = make([]byte, 256)
+	for x := uint8(0); x < 16; x++ {
+		for z := uint8(0); z < 16; z++ {
+			d.Biomes[int(x)|int(z)<<4] = byte(c.Biome(x, c.HighestBlock(x, z), z))
+		}
+	}
+	return d
+}
+
 // EncodeSubChunk encodes a sub-chunk from a chunk into bytes. An Encoding may be passed to encode either for network or
 // disk purposed, the most notable difference being that the network encoding generally uses varints and no NBT.
 func EncodeSubChunk(s *SubChunk, e Encoding, r cube.Range, ind int) []byte {
+	return encodeSubChunk(s, e, []byte{SubChunkVersion, byte(len(s.storages)), uint8(ind + (r[0] >> 4))})
+}
+
+// encodeSubChunk encodes a sub-chunk into bytes, writing the header passed before its storages.
+func encodeSubChunk(s *SubChunk, e Encoding, header []byte) []byte {
 	buf := pool.Get().(*bytes.Buffer)
 	defer func() {
 		buf.Reset()
 		pool.Put(buf)
 	}()
 
-	_, _ = buf.Write([]byte{SubChunkVersion, byte(len(s.storages)), uint8(ind + (r[0] >> 4))})
+	_, _ = buf.Write(header)
 	for _, storage := range s.storages {
 		encodePalettedStorage(buf, storage, e, BlockPaletteEncoding)
 	}
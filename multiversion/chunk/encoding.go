@@ -15,6 +15,9 @@ const (
 	// SubChunkVersion is the current version of the written sub chunks, specifying the format they are
 	// written on disk and over network.
 	SubChunkVersion = 9
+	// OldSubChunkVersion is the version of sub chunks written in the old format used before 1.18, which does not
+	// hold the Y index of the sub chunk.
+	OldSubChunkVersion = 8
 	// CurrentBlockVersion is the current version of blocks (states) of the game. This version is composed
 	// of 4 bytes indicating a version, interpreted as a big endian int. The current version represents
 	// 1.16.0.14 {1, 16, 0, 14}.
@@ -98,7 +101,7 @@ func (networkEncoding) decodePalette(buf *bytes.Buffer, blockSize paletteSize, _
 		if err := protocol.Varint32(buf, &paletteCount); err != nil {
 			return nil, fmt.Errorf("error reading palette entry count: %w", err)
 		}
-		if paletteCount <= 0 {
+		if paletteCount <= 0 || paletteCount > blockSize.maxLen() {
 			return nil, fmt.Errorf("invalid palette entry count %v", paletteCount)
 		}
 	}
@@ -126,17 +129,16 @@ func (networkPersistentEncoding) encodePalette(buf *bytes.Buffer, p *Palette, _
 	enc := nbt.NewEncoderWithEncoding(buf, nbt.NetworkLittleEndian)
 	for _, val := range p.values {
 		name, props, _ := latest.RuntimeIDToState(val)
-		_ = enc.Encode(latest.State{Name: strings.TrimPrefix("minecraft:", name), Properties: props, Version: CurrentBlockVersion})
+		_ = enc.Encode(latest.State{Name: strings.TrimPrefix(name, "minecraft:"), Properties: props, Version: CurrentBlockVersion})
 	}
 }
 func (networkPersistentEncoding) decodePalette(buf *bytes.Buffer, blockSize paletteSize, _ paletteEncoding) (*Palette, error) {
 	var paletteCount int32 = 1
 	if blockSize != 0 {
-		err := protocol.Varint32(buf, &paletteCount)
-		if err != nil {
-			panic(err)
+		if err := protocol.Varint32(buf, &paletteCount); err != nil {
+			return nil, fmt.Errorf("error reading palette entry count: %w", err)
 		}
-		if paletteCount <= 0 {
+		if paletteCount <= 0 || paletteCount > blockSize.maxLen() {
 			return nil, fmt.Errorf("invalid palette entry count %v", paletteCount)
 		}
 	}
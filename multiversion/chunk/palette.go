@@ -101,6 +101,22 @@ func (p paletteSize) padded() bool {
 	return p == 3 || p == 5 || p == 6
 }
 
+// valid checks if the paletteSize is one of the sizes that a PalettedStorage may have.
+func (p paletteSize) valid() bool {
+	for _, size := range sizes {
+		if p == size {
+			return true
+		}
+	}
+	return false
+}
+
+// maxLen returns the maximum amount of values a Palette with this size may hold. A palette can never hold more
+// values than its size allows indices for, nor more than the 4096 values a PalettedStorage has.
+func (p paletteSize) maxLen() int32 {
+	return int32(min(1<<p, 4096))
+}
+
 // paletteSizeFor finds a suitable paletteSize for the amount of values passed n.
 func paletteSizeFor(n int) paletteSize {
 	for _, size := range sizes {
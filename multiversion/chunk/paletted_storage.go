@@ -66,7 +66,7 @@ func (storage *PalettedStorage) Palette() *Palette {
 
 // At returns the value of the PalettedStorage at a given x, y and z.
 func (storage *PalettedStorage) At(x, y, z byte) uint32 {
-	return storage.palette.Value(storage.paletteIndex(x&15, y&15, z&15))
+	return storage.palette.Value(storage.validPaletteIndex(x&15, y&15, z&15))
 }
 
 // Set sets a value at a specific x, y and z. The Palette and PalettedStorage are expanded
@@ -120,6 +120,17 @@ func (storage *PalettedStorage) setPaletteIndex(x, y, z byte, i uint16) {
 	*ptr = (*ptr &^ (storage.indexMask << bitOffset)) | (uint32(i) << bitOffset)
 }
 
+// validPaletteIndex looks up the Palette index at a given x, y and z value like paletteIndex. Storages decoded
+// from the network may hold indices that point past the end of the Palette, which are not checked when decoding
+// to avoid scanning every storage twice. validPaletteIndex returns 0 for these indices, so that the first value of
+// the Palette is used instead.
+func (storage *PalettedStorage) validPaletteIndex(x, y, z byte) uint16 {
+	if i := storage.paletteIndex(x, y, z); int(i) < len(storage.palette.values) {
+		return i
+	}
+	return 0
+}
+
 // resize changes the size of a PalettedStorage to newPaletteSize. A new PalettedStorage is constructed,
 // and all values available in the current storage are set in their appropriate locations in the
 // new storage.
@@ -149,7 +160,7 @@ func (storage *PalettedStorage) compact() {
 	for x := byte(0); x < 16; x++ {
 		for y := byte(0); y < 16; y++ {
 			for z := byte(0); z < 16; z++ {
-				usedIndices[storage.paletteIndex(x, y, z)] = true
+				usedIndices[storage.validPaletteIndex(x, y, z)] = true
 			}
 		}
 	}
@@ -172,7 +183,7 @@ func (storage *PalettedStorage) compact() {
 			for z := byte(0); z < 16; z++ {
 				// Replace all usages of the old palette indexes with the new indexes using the map we
 				// produced earlier.
-				newStorage.setPaletteIndex(x, y, z, conversion[storage.paletteIndex(x, y, z)])
+				newStorage.setPaletteIndex(x, y, z, conversion[storage.validPaletteIndex(x, y, z)])
 			}
 		}
 	}
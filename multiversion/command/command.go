@@ -0,0 +1,137 @@
+// Package command implements the translation of the commands sent in the AvailableCommands packet between
+// versions. Each version that changed the way commands are sent has a Table describing the changes, which is
+// applied by the Downgrade and Upgrade functions of that version, so that commands are translated step by step
+// like all other packets.
+package command
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// typeMask is the part of the type of a command parameter that holds the ID of the parameter type. The rest of
+// the type holds flags such as protocol.CommandArgValid.
+const typeMask = 0xffff
+
+// OverloadFlag is a flag of a command overload. The flags of an overload are sent as separate fields, which are
+// combined into OverloadFlags so that they may be mapped between versions.
+type OverloadFlag uint8
+
+const (
+	// OverloadChaining is the flag of overloads that use chained subcommands.
+	OverloadChaining OverloadFlag = 1 << iota
+)
+
+// Table describes how commands of a version differ from those of the version after it.
+type Table struct {
+	// Types maps the parameter type IDs of the version after to the parameter type IDs of this version. Parameter
+	// types not in Types have the same ID in both versions.
+	Types map[uint32]uint32
+	// Overloads maps the overload flags of the version after to the overload flags of this version. A flag mapped
+	// to 0 does not exist in this version, so overloads with the flag are removed from commands sent to it. If
+	// OverloadChaining is removed, all chained subcommands are removed as well. Flags not in Overloads are the same
+	// in both versions.
+	Overloads map[OverloadFlag]OverloadFlag
+}
+
+// Downgrade returns the AvailableCommands packet passed as sent to the version of the Table, translated from the
+// version after it. The packet passed is not modified, as it may be sent to other connections as well.
+func Downgrade(pk *packet.AvailableCommands, t Table) *packet.AvailableCommands {
+	return translate(pk, t.Types, t.Overloads)
+}
+
+// Upgrade returns the AvailableCommands packet passed as sent to the version after the version of the Table,
+// translated from the version of the Table. The packet passed is not modified. Flags that do not exist in the
+// version of the Table are never set in the packet passed, so no overloads are removed.
+func Upgrade(pk *packet.AvailableCommands, t Table) *packet.AvailableCommands {
+	types := make(map[uint32]uint32, len(t.Types))
+	for newer, older := range t.Types {
+		types[older] = newer
+	}
+	overloads := make(map[OverloadFlag]OverloadFlag, len(t.Overloads))
+	for newer, older := range t.Overloads {
+		if older != 0 {
+			overloads[older] = newer
+		}
+	}
+	return translate(pk, types, overloads)
+}
+
+// translate copies the AvailableCommands packet passed, changing the parameter types and overload flags in the
+// maps passed and removing overloads with flags mapped to 0.
+func translate(pk *packet.AvailableCommands, types map[uint32]uint32, flags map[OverloadFlag]OverloadFlag) *packet.AvailableCommands {
+	noChaining := removed(OverloadChaining, flags)
+
+	translated := *pk
+	translated.Commands = make([]protocol.Command, 0, len(pk.Commands))
+	for _, c := range pk.Commands {
+		overloads := make([]protocol.CommandOverload, 0, len(c.Overloads))
+		for _, o := range c.Overloads {
+			f, ok := translateFlags(overloadFlags(o), flags)
+			if !ok {
+				continue
+			}
+			params := make([]protocol.CommandParameter, len(o.Parameters))
+			for i, p := range o.Parameters {
+				p.Type = translateType(p.Type, types)
+				params[i] = p
+			}
+			overloads = append(overloads, protocol.CommandOverload{Chaining: f&OverloadChaining != 0, Parameters: params})
+		}
+		c.Overloads = overloads
+		if noChaining {
+			c.ChainedSubcommandOffsets = []uint16{}
+		}
+		translated.Commands = append(translated.Commands, c)
+	}
+	if noChaining {
+		translated.ChainedSubcommandValues, translated.ChainedSubcommands = []string{}, []protocol.ChainedSubcommand{}
+	}
+	return &translated
+}
+
+// overloadFlags returns the flags set in the overload passed.
+func overloadFlags(o protocol.CommandOverload) (f OverloadFlag) {
+	if o.Chaining {
+		f |= OverloadChaining
+	}
+	return f
+}
+
+// translateFlags translates overload flags using the map passed. False is returned if one of the flags is mapped to
+// 0, meaning the overload cannot be sent.
+func translateFlags(f OverloadFlag, flags map[OverloadFlag]OverloadFlag) (OverloadFlag, bool) {
+	var translated OverloadFlag
+	for flag := OverloadFlag(1); flag != 0; flag <<= 1 {
+		if f&flag == 0 {
+			continue
+		}
+		if removed(flag, flags) {
+			return 0, false
+		}
+		if to, ok := flags[flag]; ok {
+			translated |= to
+			continue
+		}
+		translated |= flag
+	}
+	return translated, true
+}
+
+// removed checks if the overload flag passed is mapped to 0 in the map passed.
+func removed(flag OverloadFlag, flags map[OverloadFlag]OverloadFlag) bool {
+	to, ok := flags[flag]
+	return ok && to == 0
+}
+
+// translateType translates the type of a command parameter using the map passed. Types of parameters that point
+// to an enum or suffix rather than holding a parameter type are returned unchanged.
+func translateType(typ uint32, types map[uint32]uint32) uint32 {
+	if typ&protocol.CommandArgValid == 0 || typ&(protocol.CommandArgEnum|protocol.CommandArgSuffixed|protocol.CommandArgSoftEnum) != 0 {
+		return typ
+	}
+	if id, ok := types[typ&typeMask]; ok {
+		return typ&^typeMask | id
+	}
+	return typ
+}
@@ -0,0 +1,76 @@
+package command_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion/command"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// sample returns an AvailableCommands packet with a parameter of every kind: one with a changed type, one with an
+// unchanged type, one pointing to an enum and a chained overload.
+func sample() *packet.AvailableCommands {
+	return &packet.AvailableCommands{
+		EnumValues:              []string{"a", "b"},
+		ChainedSubcommandValues: []string{"set"},
+		Enums:                   []protocol.CommandEnum{{Type: "enum", ValueIndices: []uint{0, 1}}},
+		ChainedSubcommands:      []protocol.ChainedSubcommand{{Name: "sub", Values: []protocol.ChainedSubcommandValue{{Index: 0}}}},
+		Commands: []protocol.Command{{
+			Name:                     "test",
+			ChainedSubcommandOffsets: []uint16{0},
+			Overloads: []protocol.CommandOverload{{Parameters: []protocol.CommandParameter{
+				{Name: "string", Type: protocol.CommandArgValid | 56},
+				{Name: "int", Type: protocol.CommandArgValid | protocol.CommandArgTypeInt},
+				{Name: "enum", Type: protocol.CommandArgValid | protocol.CommandArgEnum | 56},
+			}}, {Chaining: true, Parameters: []protocol.CommandParameter{}}},
+		}},
+	}
+}
+
+// TestTable tests that a Table changes parameter types and removes chained subcommands in both directions, without
+// modifying the packet passed.
+func TestTable(t *testing.T) {
+	table := command.Table{Types: map[uint32]uint32{56: 44}, Overloads: map[command.OverloadFlag]command.OverloadFlag{command.OverloadChaining: 0}}
+
+	pk := sample()
+	downgraded := command.Downgrade(pk, table)
+	if !reflect.DeepEqual(pk, sample()) {
+		t.Fatalf("packet passed was modified: %#v", pk)
+	}
+	if len(downgraded.ChainedSubcommands) != 0 || len(downgraded.ChainedSubcommandValues) != 0 {
+		t.Errorf("expected chained subcommands to be removed, got %v", downgraded.ChainedSubcommands)
+	}
+	c := downgraded.Commands[0]
+	if len(c.Overloads) != 1 || len(c.ChainedSubcommandOffsets) != 0 {
+		t.Fatalf("expected chaining overload to be removed, got %#v", c)
+	}
+	want := []uint32{protocol.CommandArgValid | 44, protocol.CommandArgValid | protocol.CommandArgTypeInt, protocol.CommandArgValid | protocol.CommandArgEnum | 56}
+	for i, p := range c.Overloads[0].Parameters {
+		if p.Type != want[i] {
+			t.Errorf("parameter %v: expected type %x, got %x", p.Name, want[i], p.Type)
+		}
+	}
+
+	upgraded := command.Upgrade(downgraded, table)
+	if got, want := upgraded.Commands[0].Overloads[0].Parameters, pk.Commands[0].Overloads[0].Parameters; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected upgraded parameters %#v, got %#v", want, got)
+	}
+}
+
+// TestUpgradeKeepsOverloads tests that upgrading commands never removes overloads, even if the Table removes their
+// flags when downgrading.
+func TestUpgradeKeepsOverloads(t *testing.T) {
+	table := command.Table{Overloads: map[command.OverloadFlag]command.OverloadFlag{command.OverloadChaining: 0}}
+	if got := command.Upgrade(sample(), table); !reflect.DeepEqual(got, sample()) {
+		t.Errorf("expected commands to be unchanged, got %#v", got)
+	}
+}
+
+// TestZeroTable tests that a zero Table leaves commands unchanged.
+func TestZeroTable(t *testing.T) {
+	if got := command.Downgrade(sample(), command.Table{}); !reflect.DeepEqual(got, sample()) {
+		t.Errorf("expected commands to be unchanged, got %#v", got)
+	}
+}
@@ -0,0 +1,93 @@
+// Package emulation implements the emulation of packets of the latest protocol that older versions do not
+// support. Instead of dropping such a packet, an emulation replaces it with an equivalent packet that the older
+// client does understand.
+package emulation
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sirupsen/logrus"
+)
+
+// Func emulates a packet of the latest protocol for a client that does not support it. It returns the packet that
+// is sent to the client instead, or nil to drop the packet passed. The packet returned must have the same ID as the
+// packet passed, as minecraft.Conn writes the header of the packet passed before encoding the packet returned. It
+// must either be a packet that was not changed between the version of the client and the latest version, or a
+// packet of the version of the client.
+type Func func(pk packet.Packet, conn *minecraft.Conn) packet.Packet
+
+// Registry holds the emulations of a version, keyed by the ID of the packet they emulate. Each version that
+// lacks support for a packet has its own Registry, which applies to that version and all versions before it.
+// A Registry may be used by multiple goroutines simultaneously.
+type Registry struct {
+	mu         sync.RWMutex
+	emulations map[uint32]Func
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{emulations: map[uint32]Func{}}
+}
+
+// Register registers the emulation passed for packets with the ID passed, replacing any emulation that was
+// registered for the ID before. Register panics if f is nil or if the ID is not the ID of a packet of the latest
+// protocol, as emulations are registered when a program is initialised.
+func (r *Registry) Register(id uint32, f Func) {
+	if f == nil {
+		panic(fmt.Sprintf("emulation of packet %v is nil", id))
+	}
+	if !latestPacket(id) {
+		panic(fmt.Sprintf("cannot register emulation of unknown packet %v", id))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emulations[id] = f
+}
+
+// Unregister removes the emulation of packets with the ID passed, if any.
+func (r *Registry) Unregister(id uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.emulations, id)
+}
+
+// Emulate emulates the packet passed if an emulation is registered for its ID. It returns the packets to send in
+// place of the packet passed, which is either a single packet or none if it was dropped, and true. Emulate returns
+// nil and false if no emulation was registered. If the emulation returns a packet with a different ID than the
+// packet passed, which cannot be encoded, the error is logged and the packet is dropped.
+func (r *Registry) Emulate(pk packet.Packet, conn *minecraft.Conn) ([]packet.Packet, bool) {
+	r.mu.RLock()
+	f, ok := r.emulations[pk.ID()]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	emulated := f(pk, conn)
+	if emulated == nil {
+		return nil, true
+	}
+	if emulated.ID() != pk.ID() {
+		logrus.Errorf("emulation of packet %v returned packet %v with a different ID: dropping packet", pk.ID(), emulated.ID())
+		return nil, true
+	}
+	return []packet.Packet{emulated}, true
+}
+
+// Drop is a Func that drops the packet passed. It may be registered for packets that have no equivalent in an
+// older version and may safely be ignored.
+func Drop(packet.Packet, *minecraft.Conn) packet.Packet {
+	return nil
+}
+
+// latestPacket checks if the ID passed is the ID of a packet of the latest protocol, sent by either the client or
+// the server.
+func latestPacket(id uint32) bool {
+	if _, ok := packet.NewClientPool()[id]; ok {
+		return true
+	}
+	_, ok := packet.NewServerPool()[id]
+	return ok
+}
@@ -0,0 +1,60 @@
+package emulation
+
+import (
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestRegistry tests registering, replacing and unregistering emulations.
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Emulate(&packet.Text{}, nil); ok {
+		t.Fatal("expected no emulation for an empty registry")
+	}
+
+	r.Register(packet.IDText, Drop)
+	if pks, ok := r.Emulate(&packet.Text{}, nil); !ok || len(pks) != 0 {
+		t.Fatalf("expected packet to be dropped, got %v, %v", pks, ok)
+	}
+
+	r.Register(packet.IDText, func(packet.Packet, *minecraft.Conn) packet.Packet {
+		return &packet.Text{Message: "emulated"}
+	})
+	if pks, ok := r.Emulate(&packet.Text{}, nil); !ok || len(pks) != 1 || pks[0].(*packet.Text).Message != "emulated" {
+		t.Fatalf("expected replaced emulation to produce the emulated packet, got %v, %v", pks, ok)
+	}
+	if _, ok := r.Emulate(&packet.ContainerOpen{}, nil); ok {
+		t.Fatal("expected no emulation for a packet with a different ID")
+	}
+
+	r.Register(packet.IDText, func(packet.Packet, *minecraft.Conn) packet.Packet {
+		return &packet.ContainerOpen{}
+	})
+	if pks, ok := r.Emulate(&packet.Text{}, nil); !ok || len(pks) != 0 {
+		t.Fatalf("expected packet of an emulation that changes the packet ID to be dropped, got %v, %v", pks, ok)
+	}
+
+	r.Unregister(packet.IDText)
+	if _, ok := r.Emulate(&packet.Text{}, nil); ok {
+		t.Fatal("expected no emulation after unregistering")
+	}
+}
+
+// TestRegisterInvalid tests that registering an emulation for an unknown packet ID or a nil emulation panics.
+func TestRegisterInvalid(t *testing.T) {
+	for name, register := range map[string]func(r *Registry){
+		"UnknownID": func(r *Registry) { r.Register(1<<20, Drop) },
+		"NilFunc":   func(r *Registry) { r.Register(packet.IDText, nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected Register to panic")
+				}
+			}()
+			register(NewRegistry())
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package latest
 
 import (
-	"bytes"
 	_ "embed"
+	"fmt"
+	"sync"
 
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/df-mc/worldupgrader/blockupgrader"
@@ -10,79 +11,141 @@ import (
 )
 
 var (
-	//go:embed block_states.nbt
-	BlockStateData []byte
+	// ItemRuntimeIDData holds the runtime IDs of all items of the latest version, including items that Dragonfly
+	// does not implement. Items registered with Dragonfly are checked against it when the tables are built.
 	//go:embed item_runtime_ids.nbt
 	ItemRuntimeIDData []byte
 
+	// BlockStateData holds the block states of the latest version in the order of their runtime IDs.
+	//
+	// Deprecated: The block tables are built from the registry of Dragonfly, which also holds custom blocks. Use
+	// StateToRuntimeID and RuntimeIDToState instead.
+	//go:embed block_states.nbt
+	BlockStateData []byte
+
+	// once is used to build the tables below on first use, so that blocks and items registered with Dragonfly
+	// after this package is initialised are included. loadErr is the error returned by load, if any.
+	once    sync.Once
+	loadErr error
+
 	// stateToRuntimeID maps a block state hash to a runtime ID.
-	stateToRuntimeID = make(map[StateHash]uint32)
+	stateToRuntimeID map[StateHash]uint32
 	// runtimeIDToState maps a runtime ID to a state.
-	runtimeIDToState = make(map[uint32]blockupgrader.BlockState)
+	runtimeIDToState map[uint32]blockupgrader.BlockState
+	// airRuntimeID is the runtime ID of the air block.
+	airRuntimeID uint32
 
 	// itemRuntimeIDsToNames holds a map to translate item runtime IDs to string IDs.
-	itemRuntimeIDsToNames = make(map[int32]string)
+	itemRuntimeIDsToNames map[int32]string
 	// itemNamesToRuntimeIDs holds a map to translate item string IDs to runtime IDs.
-	itemNamesToRuntimeIDs = make(map[string]int32)
+	itemNamesToRuntimeIDs map[string]int32
 )
 
-// init initializes the item and block state mappings.
-func init() {
-	dec := nbt.NewDecoder(bytes.NewBuffer(BlockStateData))
-
-	// Register all block states present in the block_states.nbt file. These are all possible options registered
-	// blocks may encode to.
-	var s blockupgrader.BlockState
-	var rid uint32
-	for {
-		if err := dec.Decode(&s); err != nil {
+// load builds the block and item tables from the registry of Dragonfly, so that the runtime IDs match exactly
+// those that Dragonfly sends, including those of custom blocks and items. Custom blocks change the runtime IDs of
+// all blocks when registered, so they must be registered before the tables are first used. load returns an error
+// if the registry of Dragonfly disagrees with itself or with ItemRuntimeIDData.
+func load() error {
+	stateToRuntimeID = make(map[StateHash]uint32)
+	runtimeIDToState = make(map[uint32]blockupgrader.BlockState)
+	for rid := uint32(0); ; rid++ {
+		b, ok := world.BlockByRuntimeID(rid)
+		if !ok {
 			break
 		}
+		name, properties := b.EncodeBlock()
+		s := blockupgrader.BlockState{Name: name, Properties: properties}
 
-		upgraded := blockupgrader.Upgrade(s)
-		stateToRuntimeID[HashState(upgraded)] = rid
+		h := HashState(s)
+		if other, ok := stateToRuntimeID[h]; ok {
+			return fmt.Errorf("block state %v %v has runtime IDs %v and %v", name, properties, other, rid)
+		}
+		stateToRuntimeID[h] = rid
 		runtimeIDToState[rid] = s
-		rid++
+	}
+	airRuntimeID = world.BlockRuntimeID(nil)
+	if rid, ok := stateToRuntimeID[HashState(blockupgrader.BlockState{Name: "minecraft:air"})]; !ok || rid != airRuntimeID {
+		return fmt.Errorf("air has runtime ID %v, but Dragonfly uses %v", rid, airRuntimeID)
 	}
 
 	var m map[string]int32
-	err := nbt.Unmarshal(ItemRuntimeIDData, &m)
-	if err != nil {
-		panic(err)
+	if err := nbt.Unmarshal(ItemRuntimeIDData, &m); err != nil {
+		return fmt.Errorf("decode item runtime IDs: %w", err)
 	}
+	itemRuntimeIDsToNames = make(map[int32]string, len(m))
+	itemNamesToRuntimeIDs = make(map[string]int32, len(m))
 	for name, rid := range m {
 		itemNamesToRuntimeIDs[name] = rid
 		itemRuntimeIDsToNames[rid] = name
 	}
-	for _, it := range world.CustomItems() {
+	for _, it := range world.Items() {
 		name, _ := it.EncodeItem()
 		rid, _, _ := world.ItemRuntimeID(it)
+		if other, ok := itemNamesToRuntimeIDs[name]; ok && other != rid {
+			return fmt.Errorf("item %v has runtime ID %v, but Dragonfly uses %v: item_runtime_ids.nbt is out of date", name, other, rid)
+		}
 		itemNamesToRuntimeIDs[name] = rid
 		itemRuntimeIDsToNames[rid] = name
 	}
+	return nil
 }
 
-// StateToRuntimeID converts a name and its state properties to a runtime ID.
+// Load builds the block and item tables if they were not built yet, and returns an error if they could not be
+// built because the registry of Dragonfly does not match the latest version. The tables are otherwise built on
+// first use, which may be in the middle of translating a packet, so Load should be called once all custom blocks
+// and items are registered, but before any connection is accepted. The functions reading the tables panic if Load
+// returns an error.
+func Load() error {
+	once.Do(func() {
+		loadErr = load()
+	})
+	return loadErr
+}
+
+// mustLoad builds the block and item tables if they were not built yet, panicking if they could not be built. It
+// is called by every function reading the tables, which would otherwise silently return results from partially
+// built tables.
+func mustLoad() {
+	if err := Load(); err != nil {
+		panic(fmt.Sprintf("latest: %v", err))
+	}
+}
+
+// StateToRuntimeID converts a name and its state properties to a runtime ID. States that are not states of the
+// latest version are upgraded first.
 func StateToRuntimeID(name string, properties map[string]any) (runtimeID uint32, found bool) {
-	upgraded := blockupgrader.Upgrade(blockupgrader.BlockState{Name: name, Properties: properties})
-	rid, ok := stateToRuntimeID[HashState(upgraded)]
+	mustLoad()
+	s := blockupgrader.BlockState{Name: name, Properties: properties}
+	if rid, ok := stateToRuntimeID[HashState(s)]; ok {
+		return rid, true
+	}
+	rid, ok := stateToRuntimeID[HashState(blockupgrader.Upgrade(s))]
 	return rid, ok
 }
 
 // RuntimeIDToState converts a runtime ID to a name and its state properties.
 func RuntimeIDToState(runtimeID uint32) (name string, properties map[string]any, found bool) {
-	s := runtimeIDToState[runtimeID]
-	return s.Name, s.Properties, true
+	mustLoad()
+	s, ok := runtimeIDToState[runtimeID]
+	return s.Name, s.Properties, ok
+}
+
+// AirRuntimeID returns the runtime ID of the air block.
+func AirRuntimeID() uint32 {
+	mustLoad()
+	return airRuntimeID
 }
 
 // ItemRuntimeIDToName converts an item runtime ID to a string ID.
 func ItemRuntimeIDToName(runtimeID int32) (name string, found bool) {
+	mustLoad()
 	name, ok := itemRuntimeIDsToNames[runtimeID]
 	return name, ok
 }
 
 // ItemNameToRuntimeID converts a string ID to an item runtime ID.
 func ItemNameToRuntimeID(name string) (runtimeID int32, found bool) {
+	mustLoad()
 	rid, ok := itemNamesToRuntimeIDs[name]
 	return rid, ok
 }
@@ -0,0 +1,43 @@
+package latest_test
+
+import (
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/oomph-ac/mv/multiversion/latest"
+)
+
+// TestRegistry checks that the runtime IDs of all blocks and items registered with Dragonfly are the same as those
+// returned by the latest package.
+func TestRegistry(t *testing.T) {
+	if err := latest.Load(); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for rid := uint32(0); ; rid++ {
+		b, ok := world.BlockByRuntimeID(rid)
+		if !ok {
+			if _, _, ok := latest.RuntimeIDToState(rid); ok {
+				t.Errorf("block with runtime ID %v exists, but is not registered with Dragonfly", rid)
+			}
+			break
+		}
+		name, properties := b.EncodeBlock()
+		if got, ok := latest.StateToRuntimeID(name, properties); !ok || got != rid {
+			t.Errorf("block %v %v: expected runtime ID %v, got %v", name, properties, rid, got)
+		}
+		if got, _, ok := latest.RuntimeIDToState(rid); !ok || got != name {
+			t.Errorf("runtime ID %v: expected block %v, got %v", rid, name, got)
+		}
+	}
+	if rid := world.BlockRuntimeID(nil); latest.AirRuntimeID() != rid {
+		t.Errorf("expected air runtime ID %v, got %v", rid, latest.AirRuntimeID())
+	}
+
+	for _, it := range world.Items() {
+		name, _ := it.EncodeItem()
+		rid, _, _ := world.ItemRuntimeID(it)
+		if got, ok := latest.ItemNameToRuntimeID(name); !ok || got != rid {
+			t.Errorf("item %v: expected runtime ID %v, got %v", name, rid, got)
+		}
+	}
+}
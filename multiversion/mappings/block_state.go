@@ -21,7 +21,7 @@ type MVBlockMapping struct {
 	// LegacyAirRID is the runtime ID of the air block of that mapping.
 	LegacyAirRID uint32
 
-	// oldFormat is true if the block state data is in the old format.
+	// oldFormat is true if the version of the mapping uses the chunk format of versions before 1.18.
 	oldFormat bool
 }
 
@@ -84,3 +84,9 @@ func (m MVBlockMapping) RuntimeIDToState(runtimeID uint32) (name string, propert
 func (m MVBlockMapping) Blocks() []protocol.BlockEntry {
 	return m.blocks
 }
+
+// OldFormat returns true if the version of the mapping uses the chunk format of versions before 1.18, which has
+// 2D biomes, sub chunks without a Y index and no sub chunks below Y=0.
+func (m MVBlockMapping) OldFormat() bool {
+	return m.oldFormat
+}
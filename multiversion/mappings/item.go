@@ -2,86 +2,110 @@ package mappings
 
 import (
 	_ "embed"
+	"sync"
 
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/sandertv/gophertunnel/minecraft/nbt"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 )
 
-// MVItemMapping holds all data items related.
+// MVItemMapping holds all data items related. The item table is built when it is first used rather than when the
+// mapping is created, so that custom items registered with Dragonfly after the mapping was created, for example
+// by plugins, are included. Custom items must therefore be registered before the first connection is translated.
 type MVItemMapping struct {
+	table *itemTable
+}
+
+// itemTable holds the item table of an MVItemMapping. It is shared between all copies of the mapping.
+type itemTable struct {
+	once sync.Once
+	// itemRuntimeIDData holds the NBT encoded runtime IDs of all vanilla items the table is built from.
+	itemRuntimeIDData []byte
+
 	// items holds a list of all existing items in the game.
 	items []protocol.ItemEntry
 	// itemRuntimeIDsToNames holds a map to translate item runtime IDs to string IDs.
 	itemRuntimeIDsToNames map[int32]string
 	// itemNamesToRuntimeIDs holds a map to translate item string IDs to runtime IDs.
 	itemNamesToRuntimeIDs map[string]int32
+	// shieldID is the runtime ID of the shield item.
+	shieldID int32
 
 	recipes []protocol.Recipe
 }
 
 // ItemMapping returns MVItemMapping instance of all item entries and runtime ID maps from the resource JSON.
 func itemMapping(itemRuntimeIDData []byte) MVItemMapping {
-	var m map[string]int32
-	err := nbt.Unmarshal(itemRuntimeIDData, &m)
-	if err != nil {
-		panic(err)
-	}
+	return MVItemMapping{table: &itemTable{itemRuntimeIDData: itemRuntimeIDData}}
+}
 
-	var items []protocol.ItemEntry
-	var itemRuntimeIDsToNames = make(map[int32]string)
-	var itemNamesToRuntimeIDs = make(map[string]int32)
+// load builds the item table from the vanilla item runtime IDs and all custom items currently registered with
+// Dragonfly. It is only run once for every table.
+func (t *itemTable) load() *itemTable {
+	t.once.Do(func() {
+		var m map[string]int32
+		err := nbt.Unmarshal(t.itemRuntimeIDData, &m)
+		if err != nil {
+			panic(err)
+		}
 
-	for name, rid := range m {
-		items = append(items, protocol.ItemEntry{
-			Name:      name,
-			RuntimeID: int16(rid),
-		})
-		itemNamesToRuntimeIDs[name] = rid
-		itemRuntimeIDsToNames[rid] = name
-	}
-	for _, it := range world.CustomItems() {
-		name, _ := it.EncodeItem()
-		rid, _, _ := world.ItemRuntimeID(it)
-		items = append(items, protocol.ItemEntry{
-			Name:           name,
-			ComponentBased: true,
-			RuntimeID:      int16(rid),
-		})
-		itemNamesToRuntimeIDs[name] = rid
-		itemRuntimeIDsToNames[rid] = name
-	}
-
-	return MVItemMapping{
-		items:                 items,
-		itemRuntimeIDsToNames: itemRuntimeIDsToNames,
-		itemNamesToRuntimeIDs: itemNamesToRuntimeIDs,
-	}
+		t.itemRuntimeIDsToNames = make(map[int32]string)
+		t.itemNamesToRuntimeIDs = make(map[string]int32)
+		for name, rid := range m {
+			t.items = append(t.items, protocol.ItemEntry{
+				Name:      name,
+				RuntimeID: int16(rid),
+			})
+			t.itemNamesToRuntimeIDs[name] = rid
+			t.itemRuntimeIDsToNames[rid] = name
+		}
+		for _, it := range world.CustomItems() {
+			name, _ := it.EncodeItem()
+			rid, _, _ := world.ItemRuntimeID(it)
+			t.items = append(t.items, protocol.ItemEntry{
+				Name:           name,
+				ComponentBased: true,
+				RuntimeID:      int16(rid),
+			})
+			t.itemNamesToRuntimeIDs[name] = rid
+			t.itemRuntimeIDsToNames[rid] = name
+		}
+		t.shieldID = t.itemNamesToRuntimeIDs["minecraft:shield"]
+	})
+	return t
 }
 
 // ItemNameByID returns an item's name by its legacy ID.
 func (m MVItemMapping) ItemNameByID(id int32) (string, bool) {
 	// TODO: Properly handle item aliases.
-	name, ok := m.itemRuntimeIDsToNames[id]
+	name, ok := m.table.load().itemRuntimeIDsToNames[id]
 	return name, ok
 }
 
 // ItemIDByName returns an item's ID by its name.
 func (m MVItemMapping) ItemIDByName(name string) (int32, bool) {
 	// TODO: Properly handle item aliases.
-	id, ok := m.itemNamesToRuntimeIDs[name]
+	t := m.table.load()
+	id, ok := t.itemNamesToRuntimeIDs[name]
 	if !ok {
-		id = m.itemNamesToRuntimeIDs["minecraft:name_tag"]
+		id = t.itemNamesToRuntimeIDs["minecraft:name_tag"]
 	}
 	return id, ok
 }
 
+// ShieldID returns the runtime ID of the shield item. Item stacks holding a shield have an additional field, so
+// the runtime ID must be known to encode and decode item stacks. Protocols pass it to the readers and writers they
+// create in place of the shield ID of the latest version, which gophertunnel passes to them.
+func (m MVItemMapping) ShieldID() int32 {
+	return m.table.load().shieldID
+}
+
 // Items returns a slice of all item entries.
 func (m MVItemMapping) Items() []protocol.ItemEntry {
-	return m.items
+	return m.table.load().items
 }
 
 // Recipes returns a slice of all recipes.
 func (m MVItemMapping) Recipes() []protocol.Recipe {
-	return m.recipes
+	return m.table.load().recipes
 }
@@ -0,0 +1,169 @@
+package mappings_test
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/df-mc/dragonfly/server/item/category"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/oomph-ac/mv/multiversion/latest"
+	"github.com/oomph-ac/mv/multiversion/mappings"
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/multiversion/mv594"
+	"github.com/oomph-ac/mv/multiversion/mv618"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/oomph-ac/mv/multiversion/mv630"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/multiversion/mv662"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// customItem is a custom item registered after the mapping in TestCustomItems is created.
+type customItem struct{}
+
+func (customItem) EncodeItem() (string, int16) { return "mv:custom_item", 0 }
+func (customItem) Name() string                { return "Custom Item" }
+func (customItem) Texture() image.Image        { return image.NewRGBA(image.Rect(0, 0, 16, 16)) }
+func (customItem) Category() category.Category { return category.Items() }
+
+// TestCustomItems tests that custom items registered after a mapping is created are included in the mapping and
+// in the latest item table once they are first used.
+func TestCustomItems(t *testing.T) {
+	m := mappings.Mapping(nil, latest.ItemRuntimeIDData, false)
+	world.RegisterItem(customItem{})
+	rid, _, _ := world.ItemRuntimeID(customItem{})
+
+	if id, ok := m.ItemIDByName("mv:custom_item"); !ok || id != rid {
+		t.Errorf("expected custom item runtime ID %v in mapping, got %v", rid, id)
+	}
+	if name, ok := m.ItemNameByID(rid); !ok || name != "mv:custom_item" {
+		t.Errorf("expected custom item with runtime ID %v in mapping, got %v", rid, name)
+	}
+	if id, ok := latest.ItemNameToRuntimeID("mv:custom_item"); !ok || id != rid {
+		t.Errorf("expected custom item runtime ID %v in latest item table, got %v", rid, id)
+	}
+
+	var found bool
+	for _, entry := range m.Items() {
+		if entry.Name == "mv:custom_item" {
+			found = entry.ComponentBased && int32(entry.RuntimeID) == rid
+		}
+	}
+	if !found {
+		t.Error("expected custom item to be a component based item entry")
+	}
+}
+
+// itemMappings holds the mapping of every legacy protocol, keyed by protocol ID.
+var itemMappings = map[int32]mappings.MVMapping{
+	589: mv589.Mapping,
+	594: mv594.Mapping,
+	618: mv618.Mapping,
+	622: mv622.Mapping,
+	630: mv630.Mapping,
+	649: mv649.Mapping,
+	662: mv662.Mapping,
+	671: mv671.Mapping,
+}
+
+// TestItemPalette tests that the item palette sent to clients of each protocol in StartGame holds the runtime IDs
+// of the item table shipped with that protocol, so that it matches the item stacks downgraded using its mapping.
+func TestItemPalette(t *testing.T) {
+	latestItems := readItemTable(t, latest.ItemRuntimeIDData)
+	for _, proto := range multiversion.Legacy() {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			mapping, ok := itemMappings[proto.ID()]
+			if !ok {
+				t.Fatalf("no mapping for protocol %v", proto.ID())
+			}
+			data, err := os.ReadFile(filepath.Join("..", fmt.Sprintf("mv%v", proto.ID()), "mappings", "item_runtime_ids.nbt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			table := readItemTable(t, data)
+
+			items := make([]protocol.ItemEntry, 0, len(latestItems))
+			for name, rid := range latestItems {
+				items = append(items, protocol.ItemEntry{Name: name, RuntimeID: int16(rid)})
+			}
+			converted := proto.ConvertFromLatest(&packet.StartGame{Items: items}, new(minecraft.Conn))
+			if len(converted) != 1 {
+				t.Fatalf("expected a single StartGame packet, got %v", converted)
+			}
+			palette := reflect.ValueOf(converted[0]).Elem().FieldByName("Items").Interface().([]protocol.ItemEntry)
+
+			var expected int
+			for name := range table {
+				if _, ok := latestItems[name]; ok {
+					expected++
+				}
+			}
+			if len(palette) != expected {
+				t.Errorf("expected %v items in palette, got %v", expected, len(palette))
+			}
+			for _, item := range palette {
+				if rid, ok := table[item.Name]; !ok || int16(rid) != item.RuntimeID {
+					t.Errorf("item %v: expected runtime ID %v from item table, got %v", item.Name, rid, item.RuntimeID)
+				}
+				if name, ok := mapping.ItemNameByID(int32(item.RuntimeID)); !ok || name != item.Name {
+					t.Errorf("item %v: mapping has %v for runtime ID %v", item.Name, name, item.RuntimeID)
+				}
+			}
+		})
+	}
+}
+
+// readItemTable decodes an item table as embedded in the mappings of a protocol.
+func readItemTable(t *testing.T, data []byte) map[string]int32 {
+	var m map[string]int32
+	if err := nbt.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// TestShieldID tests that item stacks holding a shield, which have an additional field, are encoded and decoded
+// using the shield runtime ID of each protocol rather than the one of the latest version passed by connections.
+func TestShieldID(t *testing.T) {
+	latestShieldID, _ := latest.ItemNameToRuntimeID("minecraft:shield")
+	for _, proto := range multiversion.Legacy() {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			shieldID := itemMappings[proto.ID()].ShieldID()
+			want := protocol.ItemStack{ItemType: protocol.ItemType{NetworkID: shieldID}, Count: 1, NBTData: map[string]any{}, CanBePlacedOn: []string{}, CanBreak: []string{}}
+
+			buf := bytes.NewBuffer(nil)
+			protocol.NewWriter(buf, shieldID).Item(&want)
+			var got protocol.ItemStack
+			proto.NewReader(buf, latestShieldID, false).Item(&got)
+			if buf.Len() != 0 {
+				t.Fatalf("%v unread bytes left after decoding shield", buf.Len())
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("expected shield %#v, got %#v", want, got)
+			}
+
+			buf.Reset()
+			proto.NewWriter(buf, latestShieldID).Item(&want)
+			if !bytes.Equal(buf.Bytes(), encodeItem(shieldID, want)) {
+				t.Errorf("shield not encoded using shield ID %v", shieldID)
+			}
+		})
+	}
+}
+
+// encodeItem encodes an item stack using the shield ID passed.
+func encodeItem(shieldID int32, item protocol.ItemStack) []byte {
+	buf := bytes.NewBuffer(nil)
+	protocol.NewWriter(buf, shieldID).Item(&item)
+	return buf.Bytes()
+}
@@ -0,0 +1,8 @@
+package mv589
+
+import "github.com/oomph-ac/mv/multiversion/command"
+
+// Commands holds the changes to commands for 1.20.0 clients, which do not support chained subcommands.
+var Commands = command.Table{Overloads: map[command.OverloadFlag]command.OverloadFlag{
+	command.OverloadChaining: 0,
+}}
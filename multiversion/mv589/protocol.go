@@ -1,6 +1,7 @@
 package mv589
 
 import (
+	"github.com/oomph-ac/mv/multiversion/command"
 	"github.com/oomph-ac/mv/multiversion/mv589/packet"
 	"github.com/oomph-ac/mv/multiversion/mv594"
 	"github.com/oomph-ac/mv/multiversion/util"
@@ -24,31 +25,34 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(w minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(w, shieldID)
+func (Protocol) NewWriter(w minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(w, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
 	if listener {
-		return packet.NewServerPool()
+		return packet.NewClientPool()
 	}
-	return packet.NewClientPool()
+	return packet.NewServerPool()
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -60,7 +64,17 @@ func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gt
 }
 
 func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	return mv594.Upgrade(pks, conn)
+	packets := make([]gtpacket.Packet, 0, len(pks))
+	for _, pk := range pks {
+		switch pk := pk.(type) {
+		case *packet.AvailableCommands:
+			packets = append(packets, command.Upgrade(&pk.AvailableCommands, Commands))
+		default:
+			packets = append(packets, pk)
+		}
+	}
+
+	return mv594.Upgrade(packets, conn)
 }
 
 func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -68,14 +82,7 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 	for _, pk := range mv594.Downgrade(pks, conn) {
 		switch pk := pk.(type) {
 		case *gtpacket.AvailableCommands:
-			packets = append(packets, &packet.AvailableCommands{
-				EnumValues:   pk.EnumValues,
-				Suffixes:     pk.Suffixes,
-				Enums:        pk.Enums,
-				Commands:     packet.DowngradeCommands(pk.Commands),
-				DynamicEnums: pk.DynamicEnums,
-				Constraints:  pk.Constraints,
-			})
+			packets = append(packets, &packet.AvailableCommands{AvailableCommands: *command.Downgrade(pk, Commands)})
 		default:
 			packets = append(packets, pk)
 		}
@@ -26,31 +26,34 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(w minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(w, shieldID)
+func (Protocol) NewWriter(w minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(w, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
 	if listener {
-		return packet.NewServerPool()
+		return packet.NewClientPool()
 	}
-	return packet.NewClientPool()
+	return packet.NewServerPool()
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if updated, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if updated == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{updated}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -62,7 +65,104 @@ func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gt
 }
 
 func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	return mv618.Upgrade(pks, conn)
+	packets := make([]gtpacket.Packet, 0, len(pks))
+	for _, pk := range pks {
+		switch pk := pk.(type) {
+		case *packet.StartGame:
+			var editorWorldType int32 = gtpacket.EditorWorldTypeNotEditor
+			if pk.EditorWorld {
+				editorWorldType = gtpacket.EditorWorldTypeProject
+			}
+			packets = append(packets, &v662packet.StartGame{
+				EntityUniqueID:                 pk.EntityUniqueID,
+				EntityRuntimeID:                pk.EntityRuntimeID,
+				PlayerGameMode:                 pk.PlayerGameMode,
+				PlayerPosition:                 pk.PlayerPosition,
+				Pitch:                          pk.Pitch,
+				Yaw:                            pk.Yaw,
+				WorldSeed:                      pk.WorldSeed,
+				SpawnBiomeType:                 pk.SpawnBiomeType,
+				UserDefinedBiomeName:           pk.UserDefinedBiomeName,
+				Dimension:                      pk.Dimension,
+				Generator:                      pk.Generator,
+				WorldGameMode:                  pk.WorldGameMode,
+				Difficulty:                     pk.Difficulty,
+				WorldSpawn:                     pk.WorldSpawn,
+				AchievementsDisabled:           pk.AchievementsDisabled,
+				EditorWorldType:                editorWorldType,
+				CreatedInEditor:                pk.CreatedInEditor,
+				ExportedFromEditor:             pk.ExportedFromEditor,
+				DayCycleLockTime:               pk.DayCycleLockTime,
+				EducationEditionOffer:          pk.EducationEditionOffer,
+				EducationFeaturesEnabled:       pk.EducationFeaturesEnabled,
+				EducationProductID:             pk.EducationProductID,
+				RainLevel:                      pk.RainLevel,
+				LightningLevel:                 pk.LightningLevel,
+				ConfirmedPlatformLockedContent: pk.ConfirmedPlatformLockedContent,
+				MultiPlayerGame:                pk.MultiPlayerGame,
+				LANBroadcastEnabled:            pk.LANBroadcastEnabled,
+				XBLBroadcastMode:               pk.XBLBroadcastMode,
+				PlatformBroadcastMode:          pk.PlatformBroadcastMode,
+				CommandsEnabled:                pk.CommandsEnabled,
+				TexturePackRequired:            pk.TexturePackRequired,
+				GameRules:                      pk.GameRules,
+				Experiments:                    pk.Experiments,
+				ExperimentsPreviouslyToggled:   pk.ExperimentsPreviouslyToggled,
+				BonusChestEnabled:              pk.BonusChestEnabled,
+				StartWithMapEnabled:            pk.StartWithMapEnabled,
+				PlayerPermissions:              pk.PlayerPermissions,
+				ServerChunkTickRadius:          pk.ServerChunkTickRadius,
+				HasLockedBehaviourPack:         pk.HasLockedBehaviourPack,
+				HasLockedTexturePack:           pk.HasLockedTexturePack,
+				FromLockedWorldTemplate:        pk.FromLockedWorldTemplate,
+				MSAGamerTagsOnly:               pk.MSAGamerTagsOnly,
+				FromWorldTemplate:              pk.FromWorldTemplate,
+				WorldTemplateSettingsLocked:    pk.WorldTemplateSettingsLocked,
+				OnlySpawnV1Villagers:           pk.OnlySpawnV1Villagers,
+				PersonaDisabled:                pk.PersonaDisabled,
+				CustomSkinsDisabled:            pk.CustomSkinsDisabled,
+				EmoteChatMuted:                 pk.EmoteChatMuted,
+				BaseGameVersion:                pk.BaseGameVersion,
+				LimitedWorldWidth:              pk.LimitedWorldWidth,
+				LimitedWorldDepth:              pk.LimitedWorldDepth,
+				NewNether:                      pk.NewNether,
+				EducationSharedResourceURI:     pk.EducationSharedResourceURI,
+				ForceExperimentalGameplay:      pk.ForceExperimentalGameplay,
+				LevelID:                        pk.LevelID,
+				WorldName:                      pk.WorldName,
+				TemplateContentIdentity:        pk.TemplateContentIdentity,
+				Trial:                          pk.Trial,
+				PlayerMovementSettings:         pk.PlayerMovementSettings,
+				Time:                           pk.Time,
+				EnchantmentSeed:                pk.EnchantmentSeed,
+				Blocks:                         pk.Blocks,
+				Items:                          pk.Items,
+				MultiPlayerCorrelationID:       pk.MultiPlayerCorrelationID,
+				ServerAuthoritativeInventory:   pk.ServerAuthoritativeInventory,
+				GameVersion:                    pk.GameVersion,
+				PropertyData:                   pk.PropertyData,
+				ServerBlockStateChecksum:       pk.ServerBlockStateChecksum,
+				ClientSideGeneration:           pk.ClientSideGeneration,
+				WorldTemplateID:                pk.WorldTemplateID,
+				ChatRestrictionLevel:           pk.ChatRestrictionLevel,
+				DisablePlayerInteractions:      pk.DisablePlayerInteractions,
+				UseBlockNetworkIDHashes:        pk.UseBlockNetworkIDHashes,
+				ServerAuthoritativeSound:       pk.ServerAuthoritativeSound,
+			})
+		case *packet.ResourcePacksInfo:
+			packets = append(packets, &v649packet.ResourcePacksInfo{
+				TexturePackRequired: pk.TexturePackRequired,
+				HasScripts:          pk.HasScripts,
+				BehaviourPacks:      pk.BehaviourPacks,
+				TexturePacks:        pk.TexturePacks,
+				ForcingServerPacks:  pk.ForcingServerPacks,
+			})
+		default:
+			packets = append(packets, pk)
+		}
+	}
+
+	return mv618.Upgrade(packets, conn)
 }
 
 func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -147,6 +247,8 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				ServerAuthoritativeSound:       pk.ServerAuthoritativeSound,
 			})
 		case *v649packet.ResourcePacksInfo:
+			// Pack URLs are not supported by 1.20.10 and older. The packs are still announced, so that these
+			// clients download them in-band instead.
 			packets = append(packets, &packet.ResourcePacksInfo{
 				TexturePackRequired: pk.TexturePackRequired,
 				HasScripts:          pk.HasScripts,
@@ -23,12 +23,12 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(w minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(w, shieldID)
+func (Protocol) NewWriter(w minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(w, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
@@ -39,15 +39,18 @@ func (Protocol) Packets(listener bool) gtpacket.Pool {
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -59,7 +62,20 @@ func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gt
 }
 
 func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	return mv622.Upgrade(pks, conn)
+	packets := make([]gtpacket.Packet, 0, len(pks))
+	for _, pk := range pks {
+		switch pk := pk.(type) {
+		case *packet.Disconnect:
+			packets = append(packets, &gtpacket.Disconnect{
+				HideDisconnectionScreen: pk.HideDisconnectionScreen,
+				Message:                 pk.Message,
+			})
+		default:
+			packets = append(packets, pk)
+		}
+	}
+
+	return mv622.Upgrade(packets, conn)
 }
 
 func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -0,0 +1,47 @@
+package mv622
+
+import (
+	"github.com/oomph-ac/mv/multiversion/emulation"
+	"github.com/oomph-ac/mv/multiversion/mv622/packet"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	gtpacket "github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Emulations holds the emulations of packets that are not supported by 1.20.40 and older clients. Emulations may
+// be registered to it to change the way these packets are emulated.
+var Emulations = emulation.NewRegistry()
+
+func init() {
+	Emulations.Register(gtpacket.IDSetPlayerInventoryOptions, emulation.Drop)
+	Emulations.Register(gtpacket.IDPlayerToggleCrafterSlotRequest, emulation.Drop)
+	Emulations.Register(gtpacket.IDContainerOpen, EmulateCrafter)
+	Emulations.Register(gtpacket.IDShowStoreOffer, EmulateStoreOffer)
+}
+
+// EmulateCrafter emulates opening the crafter, which older clients do not have, by opening a dispenser in its
+// place. The dispenser has the same 3x3 grid of slots as the crafter, so the contents of the crafter may still be
+// shown and changed. Other containers are opened unchanged.
+func EmulateCrafter(pk gtpacket.Packet, _ *minecraft.Conn) gtpacket.Packet {
+	open := pk.(*gtpacket.ContainerOpen)
+	if open.ContainerType != protocol.ContainerTypeCrafter {
+		return pk
+	}
+	return &gtpacket.ContainerOpen{
+		WindowID:                open.WindowID,
+		ContainerType:           protocol.ContainerTypeDispenser,
+		ContainerPosition:       open.ContainerPosition,
+		ContainerEntityUniqueID: open.ContainerEntityUniqueID,
+	}
+}
+
+// EmulateStoreOffer emulates showing a store offer. Older clients can only show offers of the marketplace, so
+// offers of the dressing room and server pages are dropped. Marketplace offers are shown without the other offers
+// of the same author, as newer versions have no way to request those.
+func EmulateStoreOffer(pk gtpacket.Packet, _ *minecraft.Conn) gtpacket.Packet {
+	offer := pk.(*gtpacket.ShowStoreOffer)
+	if offer.Type != gtpacket.StoreOfferTypeMarketplace {
+		return nil
+	}
+	return &packet.ShowStoreOffer{OfferID: offer.OfferID}
+}
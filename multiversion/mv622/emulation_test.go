@@ -0,0 +1,44 @@
+package mv622_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/oomph-ac/mv/multiversion/mv622"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestEmulation tests that packets not supported by older protocols are emulated for those protocols, while newer
+// protocols receive them unchanged.
+func TestEmulation(t *testing.T) {
+	for _, proto := range multiversion.Legacy() {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			emulated := proto.ID() <= (mv622.Protocol{}).ID()
+
+			open := proto.ConvertFromLatest(&packet.ContainerOpen{WindowID: 1, ContainerType: protocol.ContainerTypeCrafter}, new(minecraft.Conn))
+			if len(open) != 1 || open[0].ID() != packet.IDContainerOpen {
+				t.Fatalf("expected a single ContainerOpen packet, got %v", open)
+			}
+			want := byte(protocol.ContainerTypeCrafter)
+			if emulated {
+				want = protocol.ContainerTypeDispenser
+			}
+			if got := reflect.ValueOf(open[0]).Elem().FieldByName("ContainerType").Interface(); got != want {
+				t.Errorf("expected container type %v, got %v", want, got)
+			}
+
+			options := proto.ConvertFromLatest(&packet.SetPlayerInventoryOptions{}, new(minecraft.Conn))
+			if emulated != (len(options) == 0) {
+				t.Errorf("expected SetPlayerInventoryOptions to be dropped: %v, got %v", emulated, options)
+			}
+
+			offer := proto.ConvertFromLatest(&packet.ShowStoreOffer{Type: packet.StoreOfferTypeServerPage}, new(minecraft.Conn))
+			if emulated != (len(offer) == 0) {
+				t.Errorf("expected server page offer to be dropped: %v, got %v", emulated, offer)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 )
 
+// IDCraftingEvent is the ID of the CraftingEvent packet, which was removed in 1.20.50.
 const IDCraftingEvent = 53
 
 // CraftingEvent is sent by the client when it crafts a particular item. Note that this packet may be fully
@@ -28,7 +29,7 @@ type CraftingEvent struct {
 
 // ID ...
 func (*CraftingEvent) ID() uint32 {
-	return 53
+	return IDCraftingEvent
 }
 
 func (pk *CraftingEvent) Marshal(io protocol.IO) {
@@ -7,6 +7,7 @@ import (
 
 func NewClientPool() packet.Pool {
 	pool := v630packet.NewClientPool()
+	pool[IDCraftingEvent] = func() packet.Packet { return &CraftingEvent{} }
 	return pool
 }
 
@@ -24,12 +24,12 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(w minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(w, shieldID)
+func (Protocol) NewWriter(w minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(w, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
@@ -40,15 +40,18 @@ func (Protocol) Packets(listener bool) gtpacket.Pool {
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -60,26 +63,38 @@ func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gt
 }
 
 func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	return mv630.Upgrade(pks, conn)
-}
-
-func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 	packets := make([]gtpacket.Packet, 0, len(pks))
-	for _, pk := range mv630.Downgrade(pks, conn) {
+	for _, pk := range pks {
 		switch pk := pk.(type) {
-		case *gtpacket.ShowStoreOffer:
-			packets = append(packets, &packet.ShowStoreOffer{
+		case *packet.ShowStoreOffer:
+			packets = append(packets, &gtpacket.ShowStoreOffer{
 				OfferID: pk.OfferID,
-				ShowAll: false, // I don't think we can really translate this one.
+				Type:    gtpacket.StoreOfferTypeMarketplace,
 			})
-		case *gtpacket.SetPlayerInventoryOptions, *gtpacket.PlayerToggleCrafterSlotRequest:
-			// These packets are not supported in 1.20.40, so we just ignore them.
+		case *packet.CraftingEvent:
+			// The client sends a CraftingEvent in addition to the ItemStackRequest that actually crafts the item,
+			// which Dragonfly already handles server-authoritatively. Translating it to a crafting action would
+			// craft twice, so it is not passed on. Interceptors are still passed the packet.
 			continue
 		default:
 			packets = append(packets, pk)
 		}
 	}
 
+	return mv630.Upgrade(packets, conn)
+}
+
+func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
+	packets := make([]gtpacket.Packet, 0, len(pks))
+	for _, pk := range mv630.Downgrade(pks, conn) {
+		if emulated, ok := Emulations.Emulate(pk, conn); ok {
+			packets = append(packets, emulated...)
+			continue
+		}
+
+		packets = append(packets, pk)
+	}
+
 	pks = nil
 	return packets
 }
@@ -0,0 +1,58 @@
+package mv630
+
+import (
+	"github.com/oomph-ac/mv/multiversion/mv630/packet"
+	"github.com/oomph-ac/mv/multiversion/util"
+	gtpacket "github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// InputFlags maps the input flags of the PlayerAuthInput packet of the latest version to those of 1.20.50 and
+// older, which do not have the flags for paddling a boat.
+var InputFlags = util.InputFlags{
+	gtpacket.InputFlagAscend:                  packet.InputFlagAscend,
+	gtpacket.InputFlagDescend:                 packet.InputFlagDescend,
+	gtpacket.InputFlagNorthJump:               packet.InputFlagNorthJump,
+	gtpacket.InputFlagJumpDown:                packet.InputFlagJumpDown,
+	gtpacket.InputFlagSprintDown:              packet.InputFlagSprintDown,
+	gtpacket.InputFlagChangeHeight:            packet.InputFlagChangeHeight,
+	gtpacket.InputFlagJumping:                 packet.InputFlagJumping,
+	gtpacket.InputFlagAutoJumpingInWater:      packet.InputFlagAutoJumpingInWater,
+	gtpacket.InputFlagSneaking:                packet.InputFlagSneaking,
+	gtpacket.InputFlagSneakDown:               packet.InputFlagSneakDown,
+	gtpacket.InputFlagUp:                      packet.InputFlagUp,
+	gtpacket.InputFlagDown:                    packet.InputFlagDown,
+	gtpacket.InputFlagLeft:                    packet.InputFlagLeft,
+	gtpacket.InputFlagRight:                   packet.InputFlagRight,
+	gtpacket.InputFlagUpLeft:                  packet.InputFlagUpLeft,
+	gtpacket.InputFlagUpRight:                 packet.InputFlagUpRight,
+	gtpacket.InputFlagWantUp:                  packet.InputFlagWantUp,
+	gtpacket.InputFlagWantDown:                packet.InputFlagWantDown,
+	gtpacket.InputFlagWantDownSlow:            packet.InputFlagWantDownSlow,
+	gtpacket.InputFlagWantUpSlow:              packet.InputFlagWantUpSlow,
+	gtpacket.InputFlagSprinting:               packet.InputFlagSprinting,
+	gtpacket.InputFlagAscendBlock:             packet.InputFlagAscendBlock,
+	gtpacket.InputFlagDescendBlock:            packet.InputFlagDescendBlock,
+	gtpacket.InputFlagSneakToggleDown:         packet.InputFlagSneakToggleDown,
+	gtpacket.InputFlagPersistSneak:            packet.InputFlagPersistSneak,
+	gtpacket.InputFlagStartSprinting:          packet.InputFlagStartSprinting,
+	gtpacket.InputFlagStopSprinting:           packet.InputFlagStopSprinting,
+	gtpacket.InputFlagStartSneaking:           packet.InputFlagStartSneaking,
+	gtpacket.InputFlagStopSneaking:            packet.InputFlagStopSneaking,
+	gtpacket.InputFlagStartSwimming:           packet.InputFlagStartSwimming,
+	gtpacket.InputFlagStopSwimming:            packet.InputFlagStopSwimming,
+	gtpacket.InputFlagStartJumping:            packet.InputFlagStartJumping,
+	gtpacket.InputFlagStartGliding:            packet.InputFlagStartGliding,
+	gtpacket.InputFlagStopGliding:             packet.InputFlagStopGliding,
+	gtpacket.InputFlagPerformItemInteraction:  packet.InputFlagPerformItemInteraction,
+	gtpacket.InputFlagPerformBlockActions:     packet.InputFlagPerformBlockActions,
+	gtpacket.InputFlagPerformItemStackRequest: packet.InputFlagPerformItemStackRequest,
+	gtpacket.InputFlagHandledTeleport:         packet.InputFlagHandledTeleport,
+	gtpacket.InputFlagEmoting:                 packet.InputFlagEmoting,
+	gtpacket.InputFlagMissedSwing:             packet.InputFlagMissedSwing,
+	gtpacket.InputFlagStartCrawling:           packet.InputFlagStartCrawling,
+	gtpacket.InputFlagStopCrawling:            packet.InputFlagStopCrawling,
+	gtpacket.InputFlagStartFlying:             packet.InputFlagStartFlying,
+	gtpacket.InputFlagStopFlying:              packet.InputFlagStopFlying,
+	gtpacket.InputFlagClientAckServerData:     packet.InputFlagClientAckServerData,
+	gtpacket.InputFlagClientPredictedVehicle:  packet.InputFlagClientPredictedVehicle,
+}
@@ -25,12 +25,12 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(w minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(w, shieldID)
+func (Protocol) NewWriter(w minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(w, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
@@ -41,15 +41,18 @@ func (Protocol) Packets(listener bool) gtpacket.Pool {
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -64,6 +67,13 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 	packets := make([]gtpacket.Packet, 0, len(pks))
 	for _, pk := range pks {
 		switch pk := pk.(type) {
+		case *gtpacket.Unknown:
+			if pk.PacketID == gtpacket.IDSetHud {
+				// The ID of the SetHud packet was not yet in use in this version, so the packet cannot be
+				// translated and is dropped.
+				continue
+			}
+			packets = append(packets, pk)
 		case *packet.PlayerAuthInput:
 			packets = append(packets, &v649packet.PlayerAuthInput{
 				Pitch:                  pk.Pitch,
@@ -71,7 +81,7 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				Position:               pk.Position,
 				MoveVector:             pk.MoveVector,
 				HeadYaw:                pk.HeadYaw,
-				InputData:              pk.InputData,
+				InputData:              InputFlags.Upgrade(pk.InputData),
 				InputMode:              pk.InputMode,
 				PlayMode:               pk.PlayMode,
 				InteractionModel:       pk.InteractionModel,
@@ -84,6 +94,20 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				AnalogueMoveVector:     pk.AnalogueMoveVector,
 				ClientPredictedVehicle: 0,
 			})
+		case *packet.LevelChunk:
+			packets = append(packets, &gtpacket.LevelChunk{
+				Position:        pk.Position,
+				HighestSubChunk: pk.HighestSubChunk,
+				SubChunkCount:   pk.SubChunkCount,
+				CacheEnabled:    pk.CacheEnabled,
+				BlobHashes:      pk.BlobHashes,
+				RawPayload:      pk.RawPayload,
+			})
+		case *packet.PlayerList:
+			packets = append(packets, &gtpacket.PlayerList{
+				ActionType: pk.ActionType,
+				Entries:    packet.UpgradePlayerEntries(pk.Entries),
+			})
 		default:
 			packets = append(packets, pk)
 		}
@@ -107,7 +131,27 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 			})
 		case *gtpacket.PlayerList:
 			packets = append(packets, &packet.PlayerList{
-				Entries: packet.DowngradePlayerEntries(pk.Entries),
+				ActionType: pk.ActionType,
+				Entries:    packet.DowngradePlayerEntries(pk.Entries),
+			})
+		case *v649packet.PlayerAuthInput:
+			packets = append(packets, &packet.PlayerAuthInput{
+				Pitch:               pk.Pitch,
+				Yaw:                 pk.Yaw,
+				Position:            pk.Position,
+				MoveVector:          pk.MoveVector,
+				HeadYaw:             pk.HeadYaw,
+				InputData:           InputFlags.Downgrade(pk.InputData),
+				InputMode:           pk.InputMode,
+				PlayMode:            pk.PlayMode,
+				InteractionModel:    pk.InteractionModel,
+				GazeDirection:       pk.GazeDirection,
+				Tick:                pk.Tick,
+				Delta:               pk.Delta,
+				ItemInteractionData: pk.ItemInteractionData,
+				ItemStackRequest:    pk.ItemStackRequest,
+				BlockActions:        pk.BlockActions,
+				AnalogueMoveVector:  pk.AnalogueMoveVector,
 			})
 		default:
 			packets = append(packets, pk)
@@ -0,0 +1,21 @@
+package mv649
+
+import (
+	"github.com/oomph-ac/mv/multiversion/command"
+	"github.com/oomph-ac/mv/multiversion/mv649/packet"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Commands holds the changes to commands for 1.20.60 and older clients, which number many command parameter types
+// differently than newer versions.
+var Commands = command.Table{Types: map[uint32]uint32{
+	protocol.CommandArgTypeEquipmentSlots: packet.CommandArgTypeEquipmentSlots,
+	protocol.CommandArgTypeString:         packet.CommandArgTypeString,
+	protocol.CommandArgTypeBlockPosition:  packet.CommandArgTypeBlockPosition,
+	protocol.CommandArgTypePosition:       packet.CommandArgTypePosition,
+	protocol.CommandArgTypeMessage:        packet.CommandArgTypeMessage,
+	protocol.CommandArgTypeRawText:        packet.CommandArgTypeRawText,
+	protocol.CommandArgTypeJSON:           packet.CommandArgTypeJSON,
+	protocol.CommandArgTypeBlockStates:    packet.CommandArgTypeBlockStates,
+	protocol.CommandArgTypeCommand:        packet.CommandArgTypeCommand,
+}}
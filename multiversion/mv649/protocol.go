@@ -5,9 +5,11 @@ import (
 	"github.com/sandertv/gophertunnel/minecraft"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 
+	"github.com/oomph-ac/mv/multiversion/command"
 	"github.com/oomph-ac/mv/multiversion/mv649/packet"
 	"github.com/oomph-ac/mv/multiversion/mv662"
 	v662packet "github.com/oomph-ac/mv/multiversion/mv662/packet"
+	"github.com/oomph-ac/mv/multiversion/mv671"
 	"github.com/oomph-ac/mv/multiversion/util"
 	gtpacket "github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
@@ -26,12 +28,12 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(r minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(r, shieldID)
+func (Protocol) NewWriter(r minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(r, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
@@ -42,15 +44,18 @@ func (Protocol) Packets(listener bool) gtpacket.Pool {
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -72,7 +77,7 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				Position:               pk.Position,
 				MoveVector:             pk.MoveVector,
 				HeadYaw:                pk.HeadYaw,
-				InputData:              pk.InputData,
+				InputData:              mv671.InputFlags.Upgrade(pk.InputData),
 				InputMode:              pk.InputMode,
 				PlayMode:               pk.PlayMode,
 				InteractionModel:       pk.InteractionModel,
@@ -92,6 +97,31 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				PageCount: pk.PageCount,
 				Position:  pk.Position,
 			})
+		case *packet.ResourcePacksInfo:
+			packets = append(packets, &gtpacket.ResourcePacksInfo{
+				TexturePackRequired: pk.TexturePackRequired,
+				HasScripts:          pk.HasScripts,
+				BehaviourPacks:      pk.BehaviourPacks,
+				TexturePacks:        pk.TexturePacks,
+				ForcingServerPacks:  pk.ForcingServerPacks,
+				PackURLs:            pk.PackURLs,
+			})
+		case *gtpacket.AvailableCommands:
+			packets = append(packets, command.Upgrade(pk, Commands))
+		case *packet.SetActorMotion:
+			packets = append(packets, &gtpacket.SetActorMotion{
+				EntityRuntimeID: pk.EntityRuntimeID,
+				Velocity:        pk.Velocity,
+			})
+		case *packet.MobEffect:
+			packets = append(packets, &gtpacket.MobEffect{
+				EntityRuntimeID: pk.EntityRuntimeID,
+				Operation:       pk.Operation,
+				EffectType:      pk.EffectType,
+				Amplifier:       pk.Amplifier,
+				Particles:       pk.Particles,
+				Duration:        pk.Duration,
+			})
 		default:
 			packets = append(packets, pk)
 		}
@@ -107,64 +137,7 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 	for _, pk := range downgraded {
 		switch pk := pk.(type) {
 		case *gtpacket.AvailableCommands:
-			// HACK!!! Why??!?!?! because GOLANG doesn't like it when i just replace p.Type :////
-			cmds := make([]protocol.Command, 0, len(pk.Commands))
-			for _, c := range pk.Commands {
-				cmd := protocol.Command{}
-				cmd.Name = c.Name
-				cmd.Description = c.Description
-				cmd.Flags = c.Flags
-				cmd.PermissionLevel = c.PermissionLevel
-				cmd.AliasesOffset = c.AliasesOffset
-				cmd.ChainedSubcommandOffsets = c.ChainedSubcommandOffsets
-				cmd.Overloads = make([]protocol.CommandOverload, 0, len(c.Overloads))
-
-				for _, o := range c.Overloads {
-					overload := protocol.CommandOverload{}
-					overload.Chaining = o.Chaining
-					overload.Parameters = make([]protocol.CommandParameter, 0, len(o.Parameters))
-
-					for _, p := range o.Parameters {
-						param := protocol.CommandParameter{}
-						param.Name = p.Name
-						param.Optional = p.Optional
-						param.Options = p.Options
-
-						var newT uint32 = protocol.CommandArgValid
-						if p.Type == (protocol.CommandArgTypeEquipmentSlots | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeEquipmentSlots
-						} else if p.Type == (protocol.CommandArgTypeString | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeString
-						} else if p.Type == (protocol.CommandArgTypeBlockPosition | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeBlockPosition
-						} else if p.Type == (protocol.CommandArgTypePosition | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypePosition
-						} else if p.Type == (protocol.CommandArgTypeMessage | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeMessage
-						} else if p.Type == (protocol.CommandArgTypeRawText | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeRawText
-						} else if p.Type == (protocol.CommandArgTypeJSON | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeJSON
-						} else if p.Type == (protocol.CommandArgTypeBlockStates | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeBlockStates
-						} else if p.Type == (protocol.CommandArgTypeCommand | protocol.CommandArgValid) {
-							newT |= packet.CommandArgTypeCommand
-						} else {
-							// We don't need to downgrade these.
-							continue
-						}
-
-						param.Type = newT
-						overload.Parameters = append(overload.Parameters, param)
-					}
-
-					cmd.Overloads = append(cmd.Overloads, overload)
-				}
-
-				cmds = append(cmds, cmd)
-			}
-			pk.Commands = cmds
-			packets = append(packets, pk)
+			packets = append(packets, command.Downgrade(pk, Commands))
 		case *gtpacket.SetActorMotion:
 			packets = append(packets, &packet.SetActorMotion{
 				Velocity:        pk.Velocity,
@@ -188,6 +161,32 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				Particles:       pk.Particles,
 				Duration:        pk.Duration,
 			})
+		case *v662packet.PlayerAuthInput:
+			packets = append(packets, &packet.PlayerAuthInput{
+				Pitch:                  pk.Pitch,
+				Yaw:                    pk.Yaw,
+				Position:               pk.Position,
+				MoveVector:             pk.MoveVector,
+				HeadYaw:                pk.HeadYaw,
+				InputData:              mv671.InputFlags.Downgrade(pk.InputData),
+				InputMode:              pk.InputMode,
+				PlayMode:               pk.PlayMode,
+				InteractionModel:       pk.InteractionModel,
+				GazeDirection:          pk.GazeDirection,
+				Tick:                   pk.Tick,
+				Delta:                  pk.Delta,
+				ItemInteractionData:    pk.ItemInteractionData,
+				ItemStackRequest:       pk.ItemStackRequest,
+				BlockActions:           pk.BlockActions,
+				ClientPredictedVehicle: pk.ClientPredictedVehicle,
+				AnalogueMoveVector:     pk.AnalogueMoveVector,
+			})
+		case *gtpacket.LecternUpdate:
+			packets = append(packets, &packet.LecternUpdate{
+				Page:      pk.Page,
+				PageCount: pk.PageCount,
+				Position:  pk.Position,
+			})
 		default:
 			packets = append(packets, pk)
 		}
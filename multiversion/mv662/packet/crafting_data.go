@@ -33,14 +33,8 @@ func (*CraftingData) ID() uint32 {
 }
 
 func (pk *CraftingData) Marshal(io protocol.IO) {
-	w := io.(*protocol.Writer)
 	protocol.FuncSlice(io, &pk.Recipes, func(x *protocol.Recipe) {
-		var recipeType int32
-		if !lookupRecipeType(*x, &recipeType) {
-			w.UnknownEnumOption(fmt.Sprintf("%T", *x), "crafting recipe type")
-		}
-		w.Varint32(&recipeType)
-		(*x).Marshal(w)
+		marshalRecipe(io, x)
 	})
 	protocol.Slice(io, &pk.PotionRecipes)
 	protocol.Slice(io, &pk.PotionContainerChangeRecipes)
@@ -48,6 +42,58 @@ func (pk *CraftingData) Marshal(io protocol.IO) {
 	io.Bool(&pk.ClearRecipes)
 }
 
+// marshalRecipe reads or writes a Recipe prefixed with its type. Recipes of which the format changed after this
+// version are decoded into the legacy recipe types of this package.
+func marshalRecipe(io protocol.IO, x *protocol.Recipe) {
+	var recipeType int32
+	if r, ok := io.(*protocol.Reader); ok {
+		r.Varint32(&recipeType)
+		if !lookupRecipe(recipeType, x) {
+			r.UnknownEnumOption(recipeType, "crafting data recipe type")
+			return
+		}
+		(*x).Unmarshal(r)
+		return
+	}
+
+	w := io.(*protocol.Writer)
+	if !lookupRecipeType(*x, &recipeType) {
+		w.UnknownEnumOption(fmt.Sprintf("%T", *x), "crafting recipe type")
+	}
+	w.Varint32(&recipeType)
+	(*x).Marshal(w)
+}
+
+// lookupRecipe looks up the Recipe for a recipe type. False is returned if not
+// found.
+func lookupRecipe(recipeType int32, x *protocol.Recipe) bool {
+	switch recipeType {
+	case protocol.RecipeShaped:
+		*x = &ShapedRecipe{}
+	case protocol.RecipeShapedChemistry:
+		*x = &ShapedChemistryRecipe{}
+	case protocol.RecipeShapeless:
+		*x = &protocol.ShapelessRecipe{}
+	case protocol.RecipeFurnace:
+		*x = &protocol.FurnaceRecipe{}
+	case protocol.RecipeFurnaceData:
+		*x = &protocol.FurnaceDataRecipe{}
+	case protocol.RecipeMulti:
+		*x = &protocol.MultiRecipe{}
+	case protocol.RecipeShulkerBox:
+		*x = &protocol.ShulkerBoxRecipe{}
+	case protocol.RecipeShapelessChemistry:
+		*x = &protocol.ShapelessChemistryRecipe{}
+	case protocol.RecipeSmithingTransform:
+		*x = &protocol.SmithingTransformRecipe{}
+	case protocol.RecipeSmithingTrim:
+		*x = &protocol.SmithingTrimRecipe{}
+	default:
+		return false
+	}
+	return true
+}
+
 // lookupRecipeType looks up the recipe type for a Recipe. False is returned if
 // none was found.
 func lookupRecipeType(x protocol.Recipe, recipeType *int32) bool {
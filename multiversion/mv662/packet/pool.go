@@ -1,6 +1,7 @@
 package packet
 
 import (
+	v671packet "github.com/oomph-ac/mv/multiversion/mv671/packet"
 	gtpacket "github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
 
@@ -20,6 +21,9 @@ const (
 func NewClientPool() gtpacket.Pool {
 	pool := gtpacket.NewClientPool()
 	pool[IDPlayerAuthInput] = func() gtpacket.Packet { return &PlayerAuthInput{} }
+	pool[v671packet.IDText] = func() gtpacket.Packet { return &v671packet.Text{} }
+	pool[v671packet.IDContainerClose] = func() gtpacket.Packet { return &v671packet.ContainerClose{} }
+	pool[v671packet.IDCodeBuilderSource] = func() gtpacket.Packet { return &v671packet.CodeBuilderSource{} }
 
 	return pool
 }
@@ -32,6 +36,9 @@ func NewServerPool() gtpacket.Pool {
 	pool[IDUpdateBlockSynced] = func() gtpacket.Packet { return &UpdateBlockSynced{} }
 	pool[IDUpdatePlayerGameType] = func() gtpacket.Packet { return &UpdatePlayerGameType{} }
 	pool[IDClientBoundDebugRenderer] = func() gtpacket.Packet { return &ClientBoundDebugRenderer{} }
+	pool[v671packet.IDText] = func() gtpacket.Packet { return &v671packet.Text{} }
+	pool[v671packet.IDContainerClose] = func() gtpacket.Packet { return &v671packet.ContainerClose{} }
+	pool[v671packet.IDCodeBuilderSource] = func() gtpacket.Packet { return &v671packet.CodeBuilderSource{} }
 
 	return pool
 }
@@ -2,6 +2,8 @@ package mv662
 
 import (
 	"github.com/oomph-ac/mv/multiversion/mv662/packet"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	v671packet "github.com/oomph-ac/mv/multiversion/mv671/packet"
 	"github.com/oomph-ac/mv/multiversion/util"
 	"github.com/sandertv/gophertunnel/minecraft"
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
@@ -22,12 +24,12 @@ func (Protocol) Encryption(key [32]byte) gtpacket.Encryption {
 	return gtpacket.NewCTREncryption(key[:])
 }
 
-func (Protocol) NewReader(r minecraft.ByteReader, shieldID int32, enableLimits bool) protocol.IO {
-	return protocol.NewReader(r, shieldID, enableLimits)
+func (Protocol) NewReader(r minecraft.ByteReader, _ int32, enableLimits bool) protocol.IO {
+	return protocol.NewReader(r, Mapping.ShieldID(), enableLimits)
 }
 
-func (Protocol) NewWriter(r minecraft.ByteWriter, shieldID int32) protocol.IO {
-	return protocol.NewWriter(r, shieldID)
+func (Protocol) NewWriter(r minecraft.ByteWriter, _ int32) protocol.IO {
+	return protocol.NewWriter(r, Mapping.ShieldID())
 }
 
 func (Protocol) Packets(listener bool) gtpacket.Pool {
@@ -38,15 +40,18 @@ func (Protocol) Packets(listener bool) gtpacket.Pool {
 }
 
 func (Protocol) ConvertToLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
-	if upgraded, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
-		if upgraded == nil {
-			return []gtpacket.Packet{}
+	upgraded := Upgrade([]gtpacket.Packet{pk}, conn)
+	packets := make([]gtpacket.Packet, 0, len(upgraded))
+	for _, pk := range upgraded {
+		if pk, ok := util.DefaultUpgrade(conn, pk, Mapping); ok {
+			if pk != nil {
+				packets = append(packets, pk)
+			}
+			continue
 		}
-
-		return Upgrade([]gtpacket.Packet{upgraded}, conn)
+		packets = append(packets, pk)
 	}
-
-	return Upgrade([]gtpacket.Packet{pk}, conn)
+	return packets
 }
 
 func (Protocol) ConvertFromLatest(pk gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
@@ -68,7 +73,7 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				Position:               pk.Position,
 				MoveVector:             pk.MoveVector,
 				HeadYaw:                pk.HeadYaw,
-				InputData:              pk.InputData,
+				InputData:              mv671.InputFlags.Upgrade(pk.InputData),
 				InputMode:              pk.InputMode,
 				PlayMode:               pk.PlayMode,
 				InteractionModel:       uint32(pk.InteractionModel),
@@ -82,6 +87,160 @@ func Upgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				ClientPredictedVehicle: pk.ClientPredictedVehicle,
 				AnalogueMoveVector:     pk.AnalogueMoveVector,
 			})
+		case *packet.ResourcePackStack:
+			packets = append(packets, &gtpacket.ResourcePackStack{
+				TexturePackRequired:          pk.TexturePackRequired,
+				BehaviourPacks:               pk.BehaviourPacks,
+				TexturePacks:                 pk.TexturePacks,
+				BaseGameVersion:              pk.BaseGameVersion,
+				Experiments:                  pk.Experiments,
+				ExperimentsPreviouslyToggled: pk.ExperimentsPreviouslyToggled,
+			})
+		case *packet.StartGame:
+			packets = append(packets, &gtpacket.StartGame{
+				EntityUniqueID:                 pk.EntityUniqueID,
+				EntityRuntimeID:                pk.EntityRuntimeID,
+				PlayerGameMode:                 pk.PlayerGameMode,
+				PlayerPosition:                 pk.PlayerPosition,
+				Pitch:                          pk.Pitch,
+				Yaw:                            pk.Yaw,
+				WorldSeed:                      pk.WorldSeed,
+				SpawnBiomeType:                 pk.SpawnBiomeType,
+				UserDefinedBiomeName:           pk.UserDefinedBiomeName,
+				Dimension:                      pk.Dimension,
+				Generator:                      pk.Generator,
+				WorldGameMode:                  pk.WorldGameMode,
+				Difficulty:                     pk.Difficulty,
+				WorldSpawn:                     pk.WorldSpawn,
+				AchievementsDisabled:           pk.AchievementsDisabled,
+				EditorWorldType:                pk.EditorWorldType,
+				CreatedInEditor:                pk.CreatedInEditor,
+				ExportedFromEditor:             pk.ExportedFromEditor,
+				DayCycleLockTime:               pk.DayCycleLockTime,
+				EducationEditionOffer:          pk.EducationEditionOffer,
+				EducationFeaturesEnabled:       pk.EducationFeaturesEnabled,
+				EducationProductID:             pk.EducationProductID,
+				RainLevel:                      pk.RainLevel,
+				LightningLevel:                 pk.LightningLevel,
+				ConfirmedPlatformLockedContent: pk.ConfirmedPlatformLockedContent,
+				MultiPlayerGame:                pk.MultiPlayerGame,
+				LANBroadcastEnabled:            pk.LANBroadcastEnabled,
+				XBLBroadcastMode:               pk.XBLBroadcastMode,
+				PlatformBroadcastMode:          pk.PlatformBroadcastMode,
+				CommandsEnabled:                pk.CommandsEnabled,
+				TexturePackRequired:            pk.TexturePackRequired,
+				GameRules:                      pk.GameRules,
+				Experiments:                    pk.Experiments,
+				ExperimentsPreviouslyToggled:   pk.ExperimentsPreviouslyToggled,
+				BonusChestEnabled:              pk.BonusChestEnabled,
+				StartWithMapEnabled:            pk.StartWithMapEnabled,
+				PlayerPermissions:              pk.PlayerPermissions,
+				ServerChunkTickRadius:          pk.ServerChunkTickRadius,
+				HasLockedBehaviourPack:         pk.HasLockedBehaviourPack,
+				HasLockedTexturePack:           pk.HasLockedTexturePack,
+				FromLockedWorldTemplate:        pk.FromLockedWorldTemplate,
+				MSAGamerTagsOnly:               pk.MSAGamerTagsOnly,
+				FromWorldTemplate:              pk.FromWorldTemplate,
+				WorldTemplateSettingsLocked:    pk.WorldTemplateSettingsLocked,
+				OnlySpawnV1Villagers:           pk.OnlySpawnV1Villagers,
+				PersonaDisabled:                pk.PersonaDisabled,
+				CustomSkinsDisabled:            pk.CustomSkinsDisabled,
+				EmoteChatMuted:                 pk.EmoteChatMuted,
+				BaseGameVersion:                pk.BaseGameVersion,
+				LimitedWorldWidth:              pk.LimitedWorldWidth,
+				LimitedWorldDepth:              pk.LimitedWorldDepth,
+				NewNether:                      pk.NewNether,
+				EducationSharedResourceURI:     pk.EducationSharedResourceURI,
+				LevelID:                        pk.LevelID,
+				WorldName:                      pk.WorldName,
+				TemplateContentIdentity:        pk.TemplateContentIdentity,
+				Trial:                          pk.Trial,
+				PlayerMovementSettings:         pk.PlayerMovementSettings,
+				Time:                           pk.Time,
+				EnchantmentSeed:                pk.EnchantmentSeed,
+				Blocks:                         pk.Blocks,
+				Items:                          pk.Items,
+				MultiPlayerCorrelationID:       pk.MultiPlayerCorrelationID,
+				ServerAuthoritativeInventory:   pk.ServerAuthoritativeInventory,
+				GameVersion:                    pk.GameVersion,
+				PropertyData:                   pk.PropertyData,
+				ServerBlockStateChecksum:       pk.ServerBlockStateChecksum,
+				ClientSideGeneration:           pk.ClientSideGeneration,
+				WorldTemplateID:                pk.WorldTemplateID,
+				ChatRestrictionLevel:           pk.ChatRestrictionLevel,
+				DisablePlayerInteractions:      pk.DisablePlayerInteractions,
+				UseBlockNetworkIDHashes:        pk.UseBlockNetworkIDHashes,
+				ServerAuthoritativeSound:       pk.ServerAuthoritativeSound,
+			})
+		case *packet.UpdateBlockSynced:
+			packets = append(packets, &gtpacket.UpdateBlockSynced{
+				Position:          pk.Position,
+				NewBlockRuntimeID: pk.NewBlockRuntimeID,
+				Flags:             pk.Flags,
+				Layer:             pk.Layer,
+				EntityUniqueID:    uint64(pk.EntityUniqueID),
+				TransitionType:    pk.TransitionType,
+			})
+		case *packet.UpdatePlayerGameType:
+			packets = append(packets, &gtpacket.UpdatePlayerGameType{
+				GameType:       pk.GameType,
+				PlayerUniqueID: pk.PlayerUniqueID,
+			})
+		case *v671packet.Text, *v671packet.ContainerClose, *v671packet.CodeBuilderSource:
+			packets = append(packets, mv671.Upgrade([]gtpacket.Packet{pk}, conn)...)
+		case *packet.ClientBoundDebugRenderer:
+			packets = append(packets, &gtpacket.ClientBoundDebugRenderer{
+				Type:     pk.Type,
+				Text:     pk.Text,
+				Position: pk.Position,
+				Red:      pk.Red,
+				Green:    pk.Green,
+				Blue:     pk.Blue,
+				Alpha:    pk.Alpha,
+				Duration: pk.Duration,
+			})
+		case *packet.CraftingData:
+			recipes := make([]protocol.Recipe, 0, len(pk.Recipes))
+			for _, r := range pk.Recipes {
+				switch r := r.(type) {
+				case *packet.ShapedRecipe:
+					recipes = append(recipes, &protocol.ShapedRecipe{
+						RecipeID:        r.RecipeID,
+						Width:           r.Width,
+						Height:          r.Height,
+						Input:           r.Input,
+						Output:          r.Output,
+						UUID:            r.UUID,
+						Block:           r.Block,
+						Priority:        r.Priority,
+						RecipeNetworkID: r.RecipeNetworkID,
+					})
+				case *packet.ShapedChemistryRecipe:
+					recipes = append(recipes, &protocol.ShapedChemistryRecipe{
+						ShapedRecipe: protocol.ShapedRecipe{
+							RecipeID:        r.RecipeID,
+							Width:           r.Width,
+							Height:          r.Height,
+							Input:           r.Input,
+							Output:          r.Output,
+							UUID:            r.UUID,
+							Block:           r.Block,
+							Priority:        r.Priority,
+							RecipeNetworkID: r.RecipeNetworkID,
+						},
+					})
+				default:
+					recipes = append(recipes, r)
+				}
+			}
+
+			packets = append(packets, &gtpacket.CraftingData{
+				Recipes:                      recipes,
+				PotionRecipes:                pk.PotionRecipes,
+				PotionContainerChangeRecipes: pk.PotionContainerChangeRecipes,
+				MaterialReducers:             pk.MaterialReducers,
+				ClearRecipes:                 pk.ClearRecipes,
+			})
 		default:
 			packets = append(packets, pk)
 		}
@@ -194,6 +353,9 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				GameType:       pk.GameType,
 				PlayerUniqueID: pk.PlayerUniqueID,
 			})
+		case *gtpacket.Text, *gtpacket.ContainerClose, *gtpacket.CodeBuilderSource:
+			// These packets were changed in 1.21.0 and have the same layout in 1.20.70 and 1.20.80.
+			packets = append(packets, mv671.Downgrade([]gtpacket.Packet{pk}, conn)...)
 		case *gtpacket.ClientBoundDebugRenderer:
 			packets = append(packets, &packet.ClientBoundDebugRenderer{
 				Type:     pk.Type,
@@ -247,6 +409,27 @@ func Downgrade(pks []gtpacket.Packet, conn *minecraft.Conn) []gtpacket.Packet {
 				MaterialReducers:             pk.MaterialReducers,
 				ClearRecipes:                 pk.ClearRecipes,
 			})
+		case *gtpacket.PlayerAuthInput:
+			packets = append(packets, &packet.PlayerAuthInput{
+				Pitch:                  pk.Pitch,
+				Yaw:                    pk.Yaw,
+				Position:               pk.Position,
+				MoveVector:             pk.MoveVector,
+				HeadYaw:                pk.HeadYaw,
+				InputData:              mv671.InputFlags.Downgrade(pk.InputData),
+				InputMode:              pk.InputMode,
+				PlayMode:               pk.PlayMode,
+				InteractionModel:       int32(pk.InteractionModel),
+				GazeDirection:          pk.GazeDirection,
+				Tick:                   pk.Tick,
+				Delta:                  pk.Delta,
+				ItemInteractionData:    pk.ItemInteractionData,
+				ItemStackRequest:       pk.ItemStackRequest,
+				BlockActions:           pk.BlockActions,
+				VehicleRotation:        pk.VehicleRotation,
+				ClientPredictedVehicle: pk.ClientPredictedVehicle,
+				AnalogueMoveVector:     pk.AnalogueMoveVector,
+			})
 		default:
 			packets = append(packets, pk)
 		}
@@ -1,6 +1,8 @@
 package packet
 
 import (
+	"fmt"
+
 	"github.com/sandertv/gophertunnel/minecraft/protocol"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 )
@@ -31,20 +33,74 @@ func (*CraftingData) ID() uint32 {
 }
 
 func (pk *CraftingData) Marshal(io protocol.IO) {
-	protocol.FuncSlice(io, &pk.Recipes, io.Recipe)
+	protocol.FuncSlice(io, &pk.Recipes, func(x *protocol.Recipe) {
+		marshalRecipe(io, x)
+	})
 	protocol.Slice(io, &pk.PotionRecipes)
 	protocol.Slice(io, &pk.PotionContainerChangeRecipes)
 	protocol.FuncSlice(io, &pk.MaterialReducers, io.MaterialReducer)
 	io.Bool(&pk.ClearRecipes)
 }
 
+// marshalRecipe reads or writes a Recipe prefixed with its type. Recipes of which the format changed after this
+// version are decoded into the legacy recipe types of this package.
+func marshalRecipe(io protocol.IO, x *protocol.Recipe) {
+	var recipeType int32
+	if r, ok := io.(*protocol.Reader); ok {
+		r.Varint32(&recipeType)
+		if !lookupRecipe(recipeType, x) {
+			r.UnknownEnumOption(recipeType, "crafting data recipe type")
+			return
+		}
+		(*x).Unmarshal(r)
+		return
+	}
+
+	w := io.(*protocol.Writer)
+	if !lookupRecipeType(*x, &recipeType) {
+		w.UnknownEnumOption(fmt.Sprintf("%T", *x), "crafting recipe type")
+	}
+	w.Varint32(&recipeType)
+	(*x).Marshal(w)
+}
+
+// lookupRecipe looks up the Recipe for a recipe type. False is returned if not
+// found.
+func lookupRecipe(recipeType int32, x *protocol.Recipe) bool {
+	switch recipeType {
+	case protocol.RecipeShapeless:
+		*x = &ShapelessRecipe{}
+	case protocol.RecipeShaped:
+		*x = &ShapedRecipe{}
+	case protocol.RecipeFurnace:
+		*x = &protocol.FurnaceRecipe{}
+	case protocol.RecipeFurnaceData:
+		*x = &protocol.FurnaceDataRecipe{}
+	case protocol.RecipeMulti:
+		*x = &protocol.MultiRecipe{}
+	case protocol.RecipeShulkerBox:
+		*x = &protocol.ShulkerBoxRecipe{}
+	case protocol.RecipeShapelessChemistry:
+		*x = &protocol.ShapelessChemistryRecipe{}
+	case protocol.RecipeShapedChemistry:
+		*x = &protocol.ShapedChemistryRecipe{}
+	case protocol.RecipeSmithingTransform:
+		*x = &protocol.SmithingTransformRecipe{}
+	case protocol.RecipeSmithingTrim:
+		*x = &protocol.SmithingTrimRecipe{}
+	default:
+		return false
+	}
+	return true
+}
+
 // lookupRecipeType looks up the recipe type for a Recipe. False is returned if
 // none was found.
 func lookupRecipeType(x protocol.Recipe, recipeType *int32) bool {
 	switch x.(type) {
-	case *protocol.ShapelessRecipe:
+	case *protocol.ShapelessRecipe, *ShapelessRecipe:
 		*recipeType = protocol.RecipeShapeless
-	case *protocol.ShapedRecipe:
+	case *protocol.ShapedRecipe, *ShapedRecipe:
 		*recipeType = protocol.RecipeShaped
 	case *protocol.FurnaceRecipe:
 		*recipeType = protocol.RecipeFurnace
@@ -11,6 +11,7 @@ const (
 	IDText              uint32 = 9
 	IDStartGame         uint32 = 11
 	IDContainerClose    uint32 = 47
+	IDCraftingData      uint32 = 52
 	IDCodeBuilderSource uint32 = 150
 )
 
@@ -28,6 +29,7 @@ func NewServerPool() gtpacket.Pool {
 	pool[IDText] = func() gtpacket.Packet { return &Text{} }
 	pool[IDStartGame] = func() gtpacket.Packet { return &StartGame{} }
 	pool[IDContainerClose] = func() gtpacket.Packet { return &ContainerClose{} }
+	pool[IDCraftingData] = func() gtpacket.Packet { return &CraftingData{} }
 	pool[IDCodeBuilderSource] = func() gtpacket.Packet { return &CodeBuilderSource{} }
 
 	return pool
@@ -0,0 +1,485 @@
+package multiversion_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// protocols holds every protocol that is run through the translation harness.
+var protocols = multiversion.Legacy()
+
+// packUUID and playerUUID are fixed UUIDs used in sample packets, so that samples constructed twice are equal.
+var (
+	packUUID   = "ff0fe4a2-1b5b-4b38-9a34-0c4e2ab4a1a6"
+	playerUUID = uuid.MustParse("5fbb3c4b-8d4c-4a1e-8a5e-7c5cf5c9a9a5")
+)
+
+// clientBound holds constructors for sample packets of the latest protocol that are sent by the server. A new
+// packet is constructed for every protocol, as translation may modify packets in place.
+var clientBound = []func() packet.Packet{
+	func() packet.Packet {
+		return &packet.Disconnect{Message: "You have been disconnected."}
+	},
+	func() packet.Packet {
+		return &packet.ShowStoreOffer{OfferID: "offer", Type: packet.StoreOfferTypeMarketplace}
+	},
+	func() packet.Packet {
+		return &packet.SetActorMotion{EntityRuntimeID: 1, Velocity: mgl32.Vec3{0.5, 1, -0.5}}
+	},
+	func() packet.Packet {
+		return &packet.MobEffect{
+			EntityRuntimeID: 1,
+			Operation:       packet.MobEffectAdd,
+			EffectType:      packet.EffectSpeed,
+			Amplifier:       2,
+			Particles:       true,
+			Duration:        200,
+		}
+	},
+	func() packet.Packet {
+		return &packet.ResourcePacksInfo{
+			TexturePackRequired: true,
+			TexturePacks: []protocol.TexturePackInfo{{
+				UUID:    packUUID,
+				Version: "1.0.0",
+				Size:    1024,
+			}},
+			BehaviourPacks: []protocol.BehaviourPackInfo{},
+			PackURLs:       []protocol.PackURL{},
+		}
+	},
+	func() packet.Packet {
+		return &packet.ResourcePackStack{
+			TexturePackRequired: true,
+			TexturePacks:        []protocol.StackResourcePack{{UUID: packUUID, Version: "1.0.0"}},
+			BehaviourPacks:      []protocol.StackResourcePack{},
+			BaseGameVersion:     "*",
+			Experiments:         []protocol.ExperimentData{},
+		}
+	},
+	func() packet.Packet {
+		return &packet.StartGame{
+			EntityUniqueID:  1,
+			EntityRuntimeID: 1,
+			PlayerGameMode:  1,
+			PlayerPosition:  mgl32.Vec3{0, 64, 0},
+			WorldName:       "World",
+			BaseGameVersion: "1.20.0",
+			GameVersion:     protocol.CurrentVersion,
+			GameRules:       []protocol.GameRule{},
+			Experiments:     []protocol.ExperimentData{},
+			Blocks:          []protocol.BlockEntry{},
+			Items:           []protocol.ItemEntry{},
+		}
+	},
+	func() packet.Packet {
+		return &packet.UpdatePlayerGameType{GameType: 1, PlayerUniqueID: 1}
+	},
+	func() packet.Packet {
+		return &packet.ClientBoundDebugRenderer{
+			Type:     packet.ClientBoundDebugRendererAddCube,
+			Text:     "cube",
+			Position: mgl32.Vec3{1, 2, 3},
+			Red:      1,
+			Alpha:    1,
+			Duration: 100,
+		}
+	},
+	func() packet.Packet {
+		return &packet.CraftingData{ClearRecipes: true}
+	},
+	func() packet.Packet {
+		return &packet.LevelChunk{
+			Position:      protocol.ChunkPos{1, 2},
+			SubChunkCount: protocol.SubChunkRequestModeLimitless,
+			RawPayload:    []byte{0},
+		}
+	},
+	func() packet.Packet {
+		return &packet.PlayerList{
+			ActionType: packet.PlayerListActionRemove,
+			Entries:    []protocol.PlayerListEntry{{UUID: playerUUID}},
+		}
+	},
+	func() packet.Packet {
+		return &packet.Text{
+			TextType:        packet.TextTypeChat,
+			SourceName:      "Steve",
+			Message:         "Hello, world!",
+			FilteredMessage: "Hello, world!",
+		}
+	},
+	func() packet.Packet {
+		return &packet.ContainerClose{WindowID: 1, ServerSide: true}
+	},
+	func() packet.Packet {
+		return &packet.SetTitle{ActionType: packet.TitleActionSetTitle, Text: "Title"}
+	},
+	func() packet.Packet {
+		return &packet.AvailableCommands{
+			Commands: []protocol.Command{{
+				Name:        "say",
+				Description: "Sends a message.",
+				// Decoding always produces a non-nil slice, which would otherwise not be equal to a nil slice.
+				ChainedSubcommandOffsets: []uint16{},
+				Overloads: []protocol.CommandOverload{{Parameters: []protocol.CommandParameter{{
+					Name: "message",
+					Type: protocol.CommandArgTypeMessage | protocol.CommandArgValid,
+				}}}, {Parameters: []protocol.CommandParameter{{
+					Name: "target",
+					Type: protocol.CommandArgTypeTarget | protocol.CommandArgValid,
+				}, {
+					Name:     "count",
+					Type:     protocol.CommandArgTypeInt | protocol.CommandArgValid,
+					Optional: true,
+				}}}},
+			}},
+		}
+	},
+}
+
+// serverBound holds constructors for sample packets of the latest protocol that are sent by the client. These are
+// translated the same way as clientBound packets when dialing a server of an older version.
+var serverBound = []func() packet.Packet{
+	func() packet.Packet {
+		return &packet.PlayerAuthInput{
+			Pitch:            10,
+			Yaw:              20,
+			Position:         mgl32.Vec3{0, 64, 0},
+			MoveVector:       mgl32.Vec2{0, 1},
+			HeadYaw:          20,
+			InputData:        packet.InputFlagUp,
+			InputMode:        packet.InputModeMouse,
+			PlayMode:         packet.PlayModeNormal,
+			InteractionModel: packet.InteractionModelCrosshair,
+			Tick:             20,
+			Delta:            mgl32.Vec3{0, -0.08, 0},
+		}
+	},
+	func() packet.Packet {
+		return &packet.LecternUpdate{Page: 1, PageCount: 2, Position: protocol.BlockPos{1, 2, 3}}
+	},
+	func() packet.Packet {
+		return &packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: "Hello, server!", FilteredMessage: "Hello, server!"}
+	},
+	func() packet.Packet {
+		return &packet.ContainerClose{WindowID: 1}
+	},
+}
+
+// TestTranslation runs every sample packet through ConvertFromLatest, encodes the result in the format of the
+// protocol, decodes it again using the packet pool of the protocol and finally passes it through
+// ConvertToLatest, checking that no data is lost along the way.
+func TestTranslation(t *testing.T) {
+	for _, proto := range protocols {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			for _, dir := range []struct {
+				name    string
+				pool    packet.Pool
+				samples []func() packet.Packet
+			}{
+				{"ClientBound", proto.Packets(false), clientBound},
+				{"ServerBound", proto.Packets(true), serverBound},
+			} {
+				t.Run(dir.name, func(t *testing.T) {
+					for _, f := range dir.samples {
+						name := reflect.TypeOf(f()).Elem().Name()
+						t.Run(name, func(t *testing.T) {
+							testTranslation(t, proto, dir.pool, f)
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// testTranslation runs a single sample packet through the translation harness.
+func testTranslation(t *testing.T, proto minecraft.Protocol, pool packet.Pool, f func() packet.Packet) {
+	conn := new(minecraft.Conn)
+	for _, legacy := range proto.ConvertFromLatest(f(), conn) {
+		decoded, err := roundTrip(proto, pool, legacy)
+		if err != nil {
+			t.Fatalf("%T: %v", legacy, err)
+		}
+		for _, latest := range proto.ConvertToLatest(decoded, conn) {
+			if reflect.TypeOf(latest) != reflect.TypeOf(f()) {
+				t.Errorf("%T: not upgraded to %T", latest, f())
+				continue
+			}
+			if err := compareFields(f(), latest); err != nil {
+				t.Errorf("%T: %v", latest, err)
+			}
+		}
+	}
+}
+
+// roundTrip encodes a packet using the writer of the protocol passed and decodes it using the pool and reader of
+// the same protocol. An error is returned if the decoded packet differs from the packet passed.
+func roundTrip(proto minecraft.Protocol, pool packet.Pool, pk packet.Packet) (decoded packet.Packet, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while decoding: %v", r)
+		}
+	}()
+
+	encoded := encode(proto, pk)
+
+	f, ok := pool[pk.ID()]
+	if !ok {
+		return nil, fmt.Errorf("packet %v not registered in pool", pk.ID())
+	}
+	decoded = f()
+	if reflect.TypeOf(decoded) != reflect.TypeOf(pk) {
+		return nil, fmt.Errorf("pool decodes packet %v as %T", pk.ID(), decoded)
+	}
+
+	if err := decode(proto, decoded, encoded); err != nil {
+		return nil, err
+	}
+	if reencoded := encode(proto, decoded); !bytes.Equal(encoded, reencoded) {
+		return nil, fmt.Errorf("re-encoded packet differs:\n%x\n%x", encoded, reencoded)
+	}
+	return decoded, nil
+}
+
+// decode decodes data into a packet using the reader of the protocol passed. An error is returned if the data
+// could not be decoded or if not all of it was read.
+func decode(proto minecraft.Protocol, pk packet.Packet, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while decoding: %v", r)
+		}
+	}()
+	buf := bytes.NewBuffer(data)
+	pk.Marshal(proto.NewReader(buf, 0, false))
+	if buf.Len() != 0 {
+		return fmt.Errorf("%v unread bytes left after decoding", buf.Len())
+	}
+	return nil
+}
+
+// encode encodes a packet using the writer of the protocol passed.
+func encode(proto minecraft.Protocol, pk packet.Packet) []byte {
+	buf := bytes.NewBuffer(nil)
+	pk.Marshal(proto.NewWriter(buf, 0))
+	return buf.Bytes()
+}
+
+// compareFields compares all fields of got with the fields of want that have the same name and type. Fields that
+// are not present in both packets are ignored, so that packets of different versions may be compared.
+func compareFields(want, got packet.Packet) error {
+	wantVal, gotVal := reflect.ValueOf(want).Elem(), reflect.ValueOf(got).Elem()
+	if wantVal.Type().Name() != gotVal.Type().Name() {
+		return fmt.Errorf("expected packet %T", want)
+	}
+	for i := 0; i < gotVal.NumField(); i++ {
+		field := gotVal.Type().Field(i)
+		wantField, ok := wantVal.Type().FieldByName(field.Name)
+		if !ok || !field.IsExported() || wantField.Type != field.Type {
+			continue
+		}
+		a, b := wantVal.FieldByIndex(wantField.Index), gotVal.Field(i)
+		if isEmpty(a) && isEmpty(b) {
+			continue
+		}
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return fmt.Errorf("field %v: expected %#v, got %#v", field.Name, a.Interface(), b.Interface())
+		}
+	}
+	return nil
+}
+
+// isEmpty checks if a value is a nil or empty slice or map. Decoding a packet always produces non-nil slices, so
+// these are considered equal.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+// layout is the wire format of a packet up to and including a protocol.
+type layout struct {
+	until int32
+	data  []byte
+}
+
+// fixtures holds sample packets of the latest protocol along with their expected wire format in the protocols
+// that changed them. The wire formats are written by hand from the layouts of the versions, rather than by
+// encoding packets, so that a translator that sends packets in the wrong format is caught.
+var fixtures = []struct {
+	serverBound bool
+	pk          func() packet.Packet
+	layouts     []layout
+}{
+	{
+		pk: clientBound[0],
+		layouts: []layout{
+			{618, wire(false, "You have been disconnected.")},
+			{671, wire(varint(0), false, "You have been disconnected.")},
+		},
+	},
+	{
+		pk: clientBound[1],
+		layouts: []layout{
+			{618, wire("offer", false)},
+			{671, wire("offer", uint8(packet.StoreOfferTypeMarketplace))},
+		},
+	},
+	{
+		pk: clientBound[2],
+		layouts: []layout{
+			{649, wire(varuint(1), mgl32.Vec3{0.5, 1, -0.5})},
+			{671, wire(varuint(1), mgl32.Vec3{0.5, 1, -0.5}, varuint(0))},
+		},
+	},
+	{
+		pk: clientBound[3],
+		layouts: []layout{
+			{649, wire(varuint(1), uint8(packet.MobEffectAdd), varint(packet.EffectSpeed), varint(2), true, varint(200))},
+			{671, wire(varuint(1), uint8(packet.MobEffectAdd), varint(packet.EffectSpeed), varint(2), true, varint(200), uint64(0))},
+		},
+	},
+	{
+		pk: clientBound[7],
+		layouts: []layout{
+			{662, wire(varint(1), varint(1))},
+			{671, wire(varint(1), varint(1), varuint(0))},
+		},
+	},
+	{
+		pk: clientBound[8],
+		layouts: []layout{
+			{662, wire(varuint(packet.ClientBoundDebugRendererAddCube), "cube", mgl32.Vec3{1, 2, 3}, float32(1), float32(0), float32(0), float32(1), uint64(100))},
+			{671, wire(uint32(packet.ClientBoundDebugRendererAddCube), "cube", mgl32.Vec3{1, 2, 3}, float32(1), float32(0), float32(0), float32(1), uint64(100))},
+		},
+	},
+	{
+		pk:      clientBound[12],
+		layouts: []layout{{671, wire(uint8(packet.TextTypeChat), false, "Steve", "Hello, world!", "", "")}},
+	},
+	{
+		pk:      clientBound[13],
+		layouts: []layout{{671, wire(uint8(1), true)}},
+	},
+	{
+		serverBound: true,
+		pk:          serverBound[1],
+		layouts: []layout{
+			{649, wire(uint8(1), uint8(2), protocol.BlockPos{1, 2, 3}, false)},
+			{671, wire(uint8(1), uint8(2), protocol.BlockPos{1, 2, 3})},
+		},
+	},
+	{
+		serverBound: true,
+		pk:          serverBound[2],
+		layouts:     []layout{{671, wire(uint8(packet.TextTypeChat), false, "Steve", "Hello, server!", "", "")}},
+	},
+	{
+		serverBound: true,
+		pk:          serverBound[3],
+		layouts:     []layout{{671, wire(uint8(1), false)}},
+	},
+}
+
+// varint and varuint are written as variable-length integers by wire.
+type (
+	varint  int64
+	varuint uint64
+)
+
+// wire writes the fields passed in order. Integers are written with a fixed size unless they are a varint or
+// varuint, and block positions are written as unsigned block positions.
+func wire(fields ...any) []byte {
+	buf := bytes.NewBuffer(nil)
+	w := protocol.NewWriter(buf, 0)
+	for _, f := range fields {
+		switch f := f.(type) {
+		case bool:
+			w.Bool(&f)
+		case uint8:
+			w.Uint8(&f)
+		case uint32:
+			w.Uint32(&f)
+		case uint64:
+			w.Uint64(&f)
+		case float32:
+			w.Float32(&f)
+		case string:
+			w.String(&f)
+		case varint:
+			v := int64(f)
+			w.Varint64(&v)
+		case varuint:
+			v := uint64(f)
+			w.Varuint64(&v)
+		case mgl32.Vec3:
+			w.Vec3(&f)
+		case protocol.BlockPos:
+			w.UBlockPos(&f)
+		default:
+			panic(fmt.Sprintf("wire: unsupported field %T", f))
+		}
+	}
+	return buf.Bytes()
+}
+
+// TestWireFormat tests that sample packets are sent to every protocol in the wire format of its version, and that
+// packets in that format are decoded by the packet pool of the protocol and upgraded to the sample packet.
+func TestWireFormat(t *testing.T) {
+	for _, proto := range protocols {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			for _, fixture := range fixtures {
+				i := slices.IndexFunc(fixture.layouts, func(l layout) bool { return proto.ID() <= l.until })
+				if i == -1 {
+					continue
+				}
+				data, pk, name := fixture.layouts[i].data, fixture.pk(), "ClientBound/"
+				if fixture.serverBound {
+					name = "ServerBound/"
+				}
+				t.Run(name+reflect.TypeOf(pk).Elem().Name(), func(t *testing.T) {
+					conn := new(minecraft.Conn)
+					legacy := proto.ConvertFromLatest(pk, conn)
+					if len(legacy) != 1 {
+						t.Fatalf("expected a single packet, got %v", legacy)
+					}
+					if legacy[0].ID() != pk.ID() {
+						t.Errorf("expected packet ID %v, got %v", pk.ID(), legacy[0].ID())
+					}
+					if encoded := encode(proto, legacy[0]); !bytes.Equal(encoded, data) {
+						t.Errorf("expected wire format\n%x\ngot\n%x", data, encoded)
+					}
+
+					f, ok := proto.Packets(fixture.serverBound)[pk.ID()]
+					if !ok {
+						t.Fatalf("packet %v not registered in pool", pk.ID())
+					}
+					decoded := f()
+					if err := decode(proto, decoded, data); err != nil {
+						t.Fatalf("%T: %v", decoded, err)
+					}
+					upgraded := proto.ConvertToLatest(decoded, conn)
+					if len(upgraded) != 1 {
+						t.Fatalf("expected a single packet, got %v", upgraded)
+					}
+					if err := compareFields(fixture.pk(), upgraded[0]); err != nil {
+						t.Errorf("%T: %v", upgraded[0], err)
+					}
+				})
+			}
+		})
+	}
+}
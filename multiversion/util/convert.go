@@ -13,8 +13,11 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// LatestAirRID is the runtime ID of the air block in the latest version of the game.
-var LatestAirRID, _ = latest.StateToRuntimeID("minecraft:air", nil)
+// LatestAirRID is the runtime ID of the air block in the latest version of the game, as registered with Dragonfly
+// when this package was initialised.
+//
+// Deprecated: Use latest.AirRuntimeID instead, which also reflects custom blocks registered after initialisation.
+var LatestAirRID = world.BlockRuntimeID(nil)
 
 // DowngradeItem downgrades the input item stack to a legacy item stack. It returns a boolean indicating if the item was
 // downgraded successfully.
@@ -66,12 +69,12 @@ func DowngradeBlockRuntimeID(input uint32, mappings mappings.MVMapping) uint32 {
 func UpgradeBlockRuntimeID(input uint32, mappings mappings.MVMapping) uint32 {
 	name, properties, ok := mappings.RuntimeIDToState(input)
 	if !ok {
-		return LatestAirRID
+		return latest.AirRuntimeID()
 	}
 
 	runtimeID, ok := latest.StateToRuntimeID(name, properties)
 	if !ok {
-		return LatestAirRID
+		return latest.AirRuntimeID()
 	}
 	return runtimeID
 }
@@ -122,6 +125,28 @@ func DefaultUpgrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVM
 		pk.Boots.Stack = UpgradeItem(pk.Boots.Stack, mapping)
 	case *packet.MobEquipment:
 		pk.NewItem.Stack = UpgradeItem(pk.NewItem.Stack, mapping)
+	case *packet.AddItemActor:
+		pk.Item.Stack = UpgradeItem(pk.Item.Stack, mapping)
+	case *packet.AddPlayer:
+		pk.HeldItem.Stack = UpgradeItem(pk.HeldItem.Stack, mapping)
+	case *packet.CreativeContent:
+		for i, item := range pk.Items {
+			pk.Items[i].Item = UpgradeItem(item.Item, mapping)
+		}
+	case *packet.InventoryContent:
+		for i, item := range pk.Content {
+			pk.Content[i].Stack = UpgradeItem(item.Stack, mapping)
+		}
+	case *packet.InventorySlot:
+		pk.NewItem.Stack = UpgradeItem(pk.NewItem.Stack, mapping)
+	case *packet.LevelEvent:
+		if pk.EventType == packet.LevelEventParticlesDestroyBlock || pk.EventType == packet.LevelEventParticlesCrackBlock {
+			pk.EventData = int32(UpgradeBlockRuntimeID(uint32(pk.EventData), mapping))
+		}
+	case *packet.LevelSoundEvent:
+		if pk.SoundType == packet.SoundEventPlace || pk.SoundType == packet.SoundEventHit || pk.SoundType == packet.SoundEventItemUseOn || pk.SoundType == packet.SoundEventLand {
+			pk.ExtraData = int32(UpgradeBlockRuntimeID(uint32(pk.ExtraData), mapping))
+		}
 	case *packet.LevelChunk:
 		if pk.SubChunkCount == protocol.SubChunkRequestModeLimited || pk.SubChunkCount == protocol.SubChunkRequestModeLimitless {
 			return pk, true
@@ -129,13 +154,13 @@ func DefaultUpgrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVM
 
 		r := world.Overworld.Range()
 		buff := bytes.NewBuffer(pk.RawPayload)
-		c, err := chunk.NetworkDecode(mapping.LegacyAirRID, buff, int(pk.SubChunkCount), conn.GameData().BaseGameVersion == "1.17.40", r)
+		c, err := chunk.NetworkDecode(mapping.LegacyAirRID, buff, int(pk.SubChunkCount), mapping.OldFormat(), r)
 		if err != nil {
 			logrus.Error(err)
 			return pk, true
 		}
 
-		upgraded := chunk.New(LatestAirRID, r)
+		upgraded := chunk.New(latest.AirRuntimeID(), r)
 		for subInd, sub := range c.Sub() {
 			for layerInd, layer := range sub.Layers() {
 				upgradedLayer := upgraded.Sub()[subInd].Layer(uint8(layerInd))
@@ -176,7 +201,7 @@ func DefaultUpgrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVM
 					return pk, true
 				}
 
-				upgraded := chunk.NewSubChunk(LatestAirRID)
+				upgraded := chunk.NewSubChunk(latest.AirRuntimeID())
 				for layerInd, layer := range subChunk.Layers() {
 					upgradedLayer := upgraded.Layer(uint8(layerInd))
 					for x := uint8(0); x < 16; x++ {
@@ -205,12 +230,6 @@ func DefaultUpgrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVM
 			pk.Blocks[i].BlockRuntimeID = UpgradeBlockRuntimeID(uint32(block.BlockRuntimeID), mapping)
 		}
 	default:
-		if pk.ID() == 53 {
-			return nil, true
-		} else if pk.ID() == 308 {
-			return nil, true
-		}
-
 		handled = false
 	}
 
@@ -221,6 +240,29 @@ func DefaultUpgrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVM
 func DefaultDowngrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.MVMapping) (packet.Packet, bool) {
 	handled := true
 	switch pk := pk.(type) {
+	case *packet.InventoryTransaction:
+		for i, action := range pk.Actions {
+			pk.Actions[i].OldItem.Stack = DowngradeItem(action.OldItem.Stack, mapping)
+			pk.Actions[i].NewItem.Stack = DowngradeItem(action.NewItem.Stack, mapping)
+		}
+		switch data := pk.TransactionData.(type) {
+		case *protocol.UseItemTransactionData:
+			if data.BlockRuntimeID > 0 {
+				data.BlockRuntimeID = DowngradeBlockRuntimeID(data.BlockRuntimeID, mapping)
+			}
+			data.HeldItem.Stack = DowngradeItem(data.HeldItem.Stack, mapping)
+		case *protocol.UseItemOnEntityTransactionData:
+			data.HeldItem.Stack = DowngradeItem(data.HeldItem.Stack, mapping)
+		case *protocol.ReleaseItemTransactionData:
+			data.HeldItem.Stack = DowngradeItem(data.HeldItem.Stack, mapping)
+		}
+	case *packet.MobArmourEquipment:
+		pk.Helmet.Stack = DowngradeItem(pk.Helmet.Stack, mapping)
+		pk.Chestplate.Stack = DowngradeItem(pk.Chestplate.Stack, mapping)
+		pk.Leggings.Stack = DowngradeItem(pk.Leggings.Stack, mapping)
+		pk.Boots.Stack = DowngradeItem(pk.Boots.Stack, mapping)
+	case *packet.MobEquipment:
+		pk.NewItem.Stack = DowngradeItem(pk.NewItem.Stack, mapping)
 	case *packet.AddItemActor:
 		pk.Item.Stack = DowngradeItem(pk.Item.Stack, mapping)
 	case *packet.AddPlayer:
@@ -250,7 +292,7 @@ func DefaultDowngrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.M
 
 		r := world.Overworld.Range()
 		buff := bytes.NewBuffer(pk.RawPayload)
-		c, err := chunk.NetworkDecode(LatestAirRID, buff, int(pk.SubChunkCount), conn.GameData().BaseGameVersion == "1.17.40", r)
+		c, err := chunk.NetworkDecode(latest.AirRuntimeID(), buff, int(pk.SubChunkCount), false, r)
 		if err != nil {
 			logrus.Error(err)
 			return pk, true
@@ -277,7 +319,12 @@ func DefaultDowngrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.M
 			}
 		}
 
-		data := chunk.Encode(downgraded, chunk.NetworkEncoding, r)
+		var data chunk.SerialisedData
+		if mapping.OldFormat() {
+			data = chunk.EncodeOldFormat(downgraded, chunk.NetworkEncoding, r)
+		} else {
+			data = chunk.Encode(downgraded, chunk.NetworkEncoding, r)
+		}
 		chunkBuf := bytes.NewBuffer(nil)
 		for i := range data.SubChunks {
 			chunkBuf.Write(data.SubChunks[i])
@@ -291,7 +338,7 @@ func DefaultDowngrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.M
 			if entry.Result == protocol.SubChunkResultSuccess && !pk.CacheEnabled {
 				buff := bytes.NewBuffer(entry.RawPayload)
 				var index byte = 0
-				subChunk, err := chunk.DecodeSubChunk(LatestAirRID, world.Overworld.Range(), buff, &index, chunk.NetworkEncoding)
+				subChunk, err := chunk.DecodeSubChunk(latest.AirRuntimeID(), world.Overworld.Range(), buff, &index, chunk.NetworkEncoding)
 				if err != nil {
 					logrus.Error(err)
 					return pk, true
@@ -330,23 +377,22 @@ func DefaultDowngrade(conn *minecraft.Conn, pk packet.Packet, mapping mappings.M
 			ClearRecipes: true,
 		}, true
 	case *packet.StartGame:
+		// The item palette must hold the runtime IDs of the version of the client, as all item stacks sent are
+		// downgraded to those runtime IDs. Items that do not exist in that version are left out, while items
+		// unknown to the latest version, such as custom items, are sent unchanged.
 		items := make([]protocol.ItemEntry, 0, len(pk.Items))
 		for _, item := range pk.Items {
-			id, ok := latest.ItemNameToRuntimeID(item.Name)
+			id, ok := mapping.ItemIDByName(item.Name)
 			if !ok {
-				items = append(items, item)
-				continue
-			}
-
-			name, ok := mapping.ItemNameByID(id)
-			if !ok {
-				items = append(items, item)
+				if _, ok := latest.ItemNameToRuntimeID(item.Name); !ok {
+					items = append(items, item)
+				}
 				continue
 			}
 
 			items = append(items, protocol.ItemEntry{
-				Name:           name,
-				RuntimeID:      item.RuntimeID,
+				Name:           item.Name,
+				RuntimeID:      int16(id),
 				ComponentBased: item.ComponentBased,
 			})
 		}
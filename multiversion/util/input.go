@@ -0,0 +1,30 @@
+package util
+
+// InputFlags maps the input flags of the PlayerAuthInput packet of the latest version to the same flags of an
+// older version. Flags are matched by name, as new flags may be inserted in between existing flags, changing their
+// bit. Flags that do not exist in the older version are not in the map. There is one InputFlags for every set of
+// input flags, which is used by all versions sharing it. Input data passes through every version in between, so
+// the flags of those versions must have the same bits as in the latest version.
+type InputFlags map[uint64]uint64
+
+// Downgrade translates input data of the latest version to input data of the older version. Flags that do not
+// exist in the older version are dropped.
+func (f InputFlags) Downgrade(data uint64) (downgraded uint64) {
+	for newer, older := range f {
+		if data&newer != 0 {
+			downgraded |= older
+		}
+	}
+	return downgraded
+}
+
+// Upgrade translates input data of the older version to input data of the latest version. Flags that are not
+// known to the older version are dropped.
+func (f InputFlags) Upgrade(data uint64) (upgraded uint64) {
+	for newer, older := range f {
+		if data&older != 0 {
+			upgraded |= newer
+		}
+	}
+	return upgraded
+}
@@ -0,0 +1,55 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/oomph-ac/mv/multiversion/mv630"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/multiversion/util"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestInputFlags tests that the input flags of the PlayerAuthInput packet are translated by name for every
+// protocol, keeping exactly the flags in the table of the version of the protocol.
+func TestInputFlags(t *testing.T) {
+	for _, proto := range multiversion.Legacy() {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			flags := mv630.InputFlags
+			if proto.ID() >= (mv649.Protocol{}).ID() {
+				flags = mv671.InputFlags
+			}
+			var want uint64
+			for latest := range flags {
+				want |= latest
+			}
+
+			conn := new(minecraft.Conn)
+			for _, legacy := range proto.ConvertFromLatest(&packet.PlayerAuthInput{InputData: ^uint64(0)}, conn) {
+				for _, pk := range proto.ConvertToLatest(legacy, conn) {
+					if got := pk.(*packet.PlayerAuthInput).InputData; got != want {
+						t.Errorf("expected input data %b, got %b", want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestInputFlagsMoved tests that input flags are moved to another bit if a flag was inserted before them. None of
+// the built-in versions moved a flag, so a table in which a flag was inserted between two others is used.
+func TestInputFlagsMoved(t *testing.T) {
+	const (
+		sneaking, inserted, sprinting = 1 << 0, 1 << 1, 1 << 2
+		olderSneaking, olderSprinting = 1 << 0, 1 << 1
+	)
+	flags := util.InputFlags{sneaking: olderSneaking, sprinting: olderSprinting}
+	if got := flags.Downgrade(sneaking | inserted | sprinting); got != olderSneaking|olderSprinting {
+		t.Errorf("expected downgraded input data %b, got %b", olderSneaking|olderSprinting, got)
+	}
+	if got := flags.Upgrade(olderSprinting); got != sprinting {
+		t.Errorf("expected upgraded input data %b, got %b", sprinting, got)
+	}
+}
@@ -0,0 +1,104 @@
+package vers
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// Option is a function that may be passed to New to change the behaviour of a Vers instance.
+type Option func(v *Vers)
+
+// WithNetwork sets the network that Vers listens on to the network registered with minecraft.RegisterNetwork
+// under the ID passed. By default, "raknet" is used.
+func WithNetwork(network string) Option {
+	return func(v *Vers) {
+		v.network = network
+	}
+}
+
+// WithProtocols adds protocols that are accepted by the listener on top of the latest protocol, which is always
+// accepted. WithProtocols may be passed multiple times, in which case all protocols passed are accepted.
+func WithProtocols(protocols ...minecraft.Protocol) Option {
+	return func(v *Vers) {
+		v.protocols = append(v.protocols, protocols...)
+	}
+}
+
+// WithListenConfig sets the minecraft.ListenConfig that the listener is created with, making all of its settings
+// available. Protocols passed using WithProtocols are added to the AcceptedProtocols of the config. Fields that
+// Dragonfly also has a setting for are filled in from the server.Config passed to Vers.Bind if left at their
+// zero value: StatusProvider, MaximumPlayers, AuthenticationDisabled, ResourcePacks and TexturePacksRequired.
+func WithListenConfig(conf minecraft.ListenConfig) Option {
+	return func(v *Vers) {
+		v.conf = conf
+	}
+}
+
+// WithInterceptors adds interceptors that are called before and after every packet of a connection is translated,
+// including packets of clients on the latest protocol. Interceptors are called in the order they are added in.
+// WithInterceptors may be passed multiple times, in which case the interceptors passed are added after those
+// passed before.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(v *Vers) {
+		v.interceptors = append(v.interceptors, interceptors...)
+	}
+}
+
+// WithResourcePacks adds resource packs that are only sent to clients with a protocol ID between min and max,
+// inclusive, so that older clients may be sent packs with a lower min_engine_version or backported textures. The
+// packs are selected during the resource pack handshake, once the protocol of the client is known. Resource packs
+// of the server.Config or the minecraft.ListenConfig are still sent to all clients. WithResourcePacks may be passed
+// multiple times, for example once for each protocol:
+//
+//	vers.WithResourcePacks(mv589.Protocol{}.ID(), mv622.Protocol{}.ID(), oldPack),
+//	vers.WithResourcePacks(mv630.Protocol{}.ID(), minecraft.DefaultProtocol.ID(), newPack),
+func WithResourcePacks(min, max int32, packs ...*resource.Pack) Option {
+	return func(v *Vers) {
+		v.packs = append(v.packs, versionPacks{min: min, max: max, packs: packs})
+	}
+}
+
+// WithPackDowngrading makes the listener downgrade the manifests of the resource packs of the server.Config or
+// the minecraft.ListenConfig for every accepted protocol using DowngradePacks, so that a single set of resource
+// packs is accepted by clients of every version. Resource packs added using WithResourcePacks are not downgraded.
+// If report is not nil, it is called with a PackReport for every resource pack downgraded when the listener is
+// created.
+func WithPackDowngrading(report func(r PackReport)) Option {
+	return func(v *Vers) {
+		v.downgrade, v.packReport = true, report
+	}
+}
+
+// WithPackURLs adds resource packs that are downloaded from the URLs passed and sent to clients of every protocol.
+// Clients that support pack URLs are told to download the packs from these URLs, while older clients are sent
+// the packs in-band. The content of the packs is cached in the directory passed, so that the packs are only
+// downloaded again if they changed. These packs are not downgraded by WithPackDowngrading, as clients downloading
+// them from the URL would otherwise get a different pack. Resource packs of the server.Config or the
+// minecraft.ListenConfig that have a download URL, such as those read using resource.ReadURL, are sent to older
+// clients in-band in the same way.
+func WithPackURLs(cache string, urls ...string) Option {
+	return func(v *Vers) {
+		for _, url := range urls {
+			v.packURLs = append(v.packURLs, packURL{url: url, cache: cache})
+		}
+	}
+}
+
+// WithUnsupportedPolicy sets the UnsupportedPolicy for clients joining with any of the protocol IDs passed, or
+// the default policy for all protocols up to the current protocol that are not accepted if no IDs are passed. A
+// policy set for a protocol ID takes precedence over the default policy. Protocols that are accepted by the
+// listener are never affected.
+func WithUnsupportedPolicy(policy UnsupportedPolicy, ids ...int32) Option {
+	return func(v *Vers) {
+		if len(ids) == 0 {
+			v.unsupportedPolicy = policy
+			return
+		}
+		if v.unsupported == nil {
+			v.unsupported = map[int32]UnsupportedPolicy{}
+		}
+		for _, id := range ids {
+			v.unsupported[id] = policy
+		}
+	}
+}
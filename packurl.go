@@ -0,0 +1,115 @@
+package vers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// packURL is a URL that a resource pack is downloaded from, together with the directory it is cached in.
+type packURL struct {
+	url, cache string
+}
+
+// packClient is the http.Client used to download resource packs. Packs are downloaded when the listener is
+// created, so a timeout is set to prevent an unreachable URL from blocking the listener forever.
+var packClient = &http.Client{Timeout: time.Second * 30}
+
+// loadPackURLs loads the resource packs of all URLs passed. It returns the packs, which are sent to clients of
+// every protocol, and their URLs keyed by pack key.
+func loadPackURLs(urls []packURL) ([]versionPacks, map[string]string, error) {
+	if len(urls) == 0 {
+		return nil, nil, nil
+	}
+	vp := versionPacks{min: math.MinInt32, max: math.MaxInt32}
+	m := make(map[string]string, len(urls))
+	for _, u := range urls {
+		pack, err := ReadPackURL(u.url, u.cache)
+		if err != nil {
+			return nil, nil, err
+		}
+		vp.packs = append(vp.packs, pack)
+		m[packKey(pack)] = u.url
+	}
+	return []versionPacks{vp}, m, nil
+}
+
+// ReadPackURL reads the resource pack found at the URL passed, caching its content in the cache directory
+// passed. If the pack was cached before, it is only downloaded again if it changed, and the cached pack is used
+// if the URL cannot be reached or does not respond in time. Unlike resource.ReadURL, the pack returned has no
+// download URL, so that it is sent in-band to clients unless the URL is announced separately, as is done for
+// packs added using WithPackURLs.
+func ReadPackURL(url, cache string) (*resource.Pack, error) {
+	sum := sha256.Sum256([]byte(url))
+	name := filepath.Join(cache, hex.EncodeToString(sum[:]))
+	content, err := downloadPack(url, name)
+	if err != nil {
+		return nil, fmt.Errorf("read resource pack %v: %w", url, err)
+	}
+	pack, err := resource.Read(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("read resource pack %v: %w", url, err)
+	}
+	return pack, nil
+}
+
+// downloadPack downloads the content of the resource pack at the URL passed, using the cached content found at
+// the path passed if it has not changed according to the ETag stored next to it.
+func downloadPack(url, path string) ([]byte, error) {
+	cached, cacheErr := os.ReadFile(path + ".mcpack")
+	etag, _ := os.ReadFile(path + ".etag")
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cacheErr == nil && len(etag) > 0 {
+		req.Header.Set("If-None-Match", string(etag))
+	}
+	resp, err := packClient.Do(req)
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cacheErr == nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		if cacheErr == nil && resp.StatusCode >= http.StatusInternalServerError {
+			return cached, nil
+		}
+		return nil, fmt.Errorf("download: %v", resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create cache: %w", err)
+	}
+	if err := os.WriteFile(path+".mcpack", content, 0644); err != nil {
+		return nil, fmt.Errorf("write cache: %w", err)
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		err = os.WriteFile(path+".etag", []byte(etag), 0644)
+	} else {
+		err = os.Remove(path + ".etag")
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("write cache: %w", err)
+	}
+	return content, nil
+}
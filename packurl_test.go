@@ -0,0 +1,187 @@
+package vers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/multiversion/mv594"
+	v594packet "github.com/oomph-ac/mv/multiversion/mv594/packet"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// TestReadPackURL tests that resource packs read from a URL are cached, and only downloaded again if changed.
+func TestReadPackURL(t *testing.T) {
+	srv, downloads := packServer(t, testPack(t, "cdn"))
+	cache := t.TempDir()
+
+	first, err := ReadPackURL(srv.URL, cache)
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	second, err := ReadPackURL(srv.URL, cache)
+	if err != nil {
+		t.Fatalf("read cached pack: %v", err)
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected pack to be downloaded once, got %v downloads", n)
+	}
+	srv.Close()
+
+	third, err := ReadPackURL(srv.URL, cache)
+	if err != nil {
+		t.Fatalf("read cached pack while offline: %v", err)
+	}
+	if first.Name() != "cdn" || first.Checksum() != second.Checksum() || first.Checksum() != third.Checksum() {
+		t.Errorf("expected the same pack to be read every time")
+	}
+	if first.DownloadURL() != "" {
+		t.Errorf("expected pack without download URL, got %v", first.DownloadURL())
+	}
+}
+
+// TestReadPackURLTimeout tests that reading a resource pack from a URL that does not respond does not block
+// forever, and that the cached pack is used instead if there is one.
+func TestReadPackURLTimeout(t *testing.T) {
+	srv, _ := packServer(t, testPack(t, "cdn"))
+	defer srv.Close()
+	cache := t.TempDir()
+	if _, err := ReadPackURL(srv.URL, cache); err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+
+	hang := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hang
+	}))
+	defer hanging.Close()
+	defer close(hang)
+
+	timeout := packClient.Timeout
+	packClient.Timeout = time.Millisecond * 100
+	defer func() { packClient.Timeout = timeout }()
+
+	if _, err := ReadPackURL(hanging.URL, t.TempDir()); err == nil {
+		t.Errorf("expected error reading pack from URL that does not respond")
+	}
+	// Cache the pack under the URL of the hanging server, so that it is used once the request times out.
+	from, to := sha256.Sum256([]byte(srv.URL)), sha256.Sum256([]byte(hanging.URL))
+	if err := os.Rename(filepath.Join(cache, hex.EncodeToString(from[:])+".mcpack"), filepath.Join(cache, hex.EncodeToString(to[:])+".mcpack")); err != nil {
+		t.Fatalf("move cache: %v", err)
+	}
+	if pack, err := ReadPackURL(hanging.URL, cache); err != nil || pack.Name() != "cdn" {
+		t.Errorf("expected cached pack to be used, got %v, %v", pack, err)
+	}
+}
+
+// TestPackURLs tests that resource packs added using WithPackURLs are announced with their URL to clients that
+// support it, and are sent in-band to clients that do not.
+func TestPackURLs(t *testing.T) {
+	srv, _ := packServer(t, testPack(t, "cdn"))
+	defer srv.Close()
+
+	conf := newConfig()
+	v := New(":0", WithNetwork(verstest.Network), WithProtocols(mv594.Protocol{}), WithPackURLs(t.TempDir(), srv.URL))
+	cfg, err := v.listenConfig(*conf)
+	if err != nil {
+		t.Fatalf("listen config: %v", err)
+	}
+	for _, proto := range cfg.AcceptedProtocols {
+		info := &packet.ResourcePacksInfo{TexturePacks: []protocol.TexturePackInfo{{UUID: cfg.ResourcePacks[0].UUID(), Version: cfg.ResourcePacks[0].Version()}}}
+		switch pk := proto.ConvertFromLatest(info, new(minecraft.Conn))[0].(type) {
+		case *packet.ResourcePacksInfo:
+			if len(pk.PackURLs) != 1 || pk.PackURLs[0].URL != srv.URL {
+				t.Errorf("%v: expected pack URL %v, got %+v", proto.Ver(), srv.URL, pk.PackURLs)
+			}
+		case *v594packet.ResourcePacksInfo:
+			if proto.ID() != (mv594.Protocol{}).ID() || len(pk.TexturePacks) != 1 {
+				t.Errorf("%v: expected pack to be announced without URL, got %+v", proto.Ver(), pk)
+			}
+		default:
+			t.Errorf("%v: unexpected packet %T", proto.Ver(), pk)
+		}
+	}
+
+	v.Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	client, conn, err := join(l, mv594.Protocol{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+	if packs := client.ResourcePacks(); len(packs) != 1 || packs[0].Name() != "cdn" {
+		t.Errorf("expected pack to be downloaded in-band, got %v", packs)
+	}
+}
+
+// TestResourcePackURLs tests that resource packs of the server.Config with a download URL are downloaded by
+// clients of every protocol, including those that do not support pack URLs and download them in-band.
+func TestResourcePackURLs(t *testing.T) {
+	srv, _ := packServer(t, testPack(t, "cdn"))
+	defer srv.Close()
+	pack, err := resource.ReadURL(srv.URL)
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+
+	conf := newConfig()
+	conf.Resources = []*resource.Pack{pack}
+	New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	for _, proto := range append(slices.Clone(protocols), minecraft.DefaultProtocol) {
+		client, conn, err := join(l, proto)
+		if err != nil {
+			t.Fatalf("%v: %v", proto.Ver(), err)
+		}
+		if packs := client.ResourcePacks(); len(packs) != 1 || packs[0].Checksum() != pack.Checksum() {
+			t.Errorf("%v: expected pack to be downloaded, got %v", proto.Ver(), packs)
+		}
+		_ = client.Close()
+		_ = conn.Close()
+	}
+}
+
+// packServer starts an HTTP server that serves the resource pack passed with an ETag. It returns the server and
+// the number of times the pack was downloaded.
+func packServer(t *testing.T, pack *resource.Pack) (*httptest.Server, *atomic.Int32) {
+	content := make([]byte, pack.Len())
+	if _, err := pack.ReadAt(content, 0); err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	etag := fmt.Sprintf("%q", uuid.NewString())
+	downloads := new(atomic.Int32)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "pack.mcpack", time.Time{}, bytes.NewReader(content))
+	})), downloads
+}
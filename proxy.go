@@ -0,0 +1,194 @@
+package vers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/df-mc/dragonfly/server"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sirupsen/logrus"
+)
+
+// ProxyConfig holds the settings of a Proxy.
+type ProxyConfig struct {
+	// Upstream is the address of the server that clients are relayed to. The server must accept the latest
+	// protocol and have authentication disabled, as the proxy cannot log in on behalf of clients.
+	Upstream string
+	// UpstreamNetwork is the network used to connect to the upstream server. By default, "raknet" is used.
+	UpstreamNetwork string
+	// Dialer is the minecraft.Dialer used to connect to the upstream server. The identity and client data of
+	// the client that is relayed are set for every connection, and the Protocol is always the latest one.
+	Dialer minecraft.Dialer
+	// Name is the name of the proxy as displayed in the server list, unless a StatusProvider is set using
+	// WithListenConfig.
+	Name string
+	// Log is the logger used by the proxy. By default, a new logrus.Logger is used.
+	Log server.Logger
+}
+
+// Proxy is a multi-version proxy that may be put in front of any server that runs the latest version. Clients
+// are accepted on every protocol of the Vers instance and relayed to the upstream server on the latest protocol.
+// Packets are translated using the ConvertToLatest and ConvertFromLatest methods of the protocol of the client.
+type Proxy struct {
+	v    *Vers
+	conf ProxyConfig
+
+	l      *minecraft.Listener
+	once   sync.Once
+	closed atomic.Bool
+	wg     sync.WaitGroup
+}
+
+// NewProxy creates a new Proxy that listens for clients using the settings of the Vers instance passed.
+func NewProxy(v *Vers, conf ProxyConfig) *Proxy {
+	if conf.UpstreamNetwork == "" {
+		conf.UpstreamNetwork = "raknet"
+	}
+	if conf.Log == nil {
+		conf.Log = logrus.New()
+	}
+	conf.Dialer.Protocol = nil
+	return &Proxy{v: v, conf: conf}
+}
+
+// ListenAndServe starts listening for clients and relays them to the upstream server until the Proxy is closed.
+func (p *Proxy) ListenAndServe() error {
+	if err := p.Listen(); err != nil {
+		return err
+	}
+	return p.Serve()
+}
+
+// Listen starts listening for clients on the address of the Vers instance of the Proxy.
+func (p *Proxy) Listen() error {
+	cfg, err := p.v.listenConfig(server.Config{Name: p.conf.Name})
+	if err != nil {
+		return err
+	}
+	l, err := cfg.Listen(p.v.listenNetwork(cfg.AcceptedProtocols), p.v.addr)
+	if err != nil {
+		return err
+	}
+	p.l = l
+	p.conf.Log.Infof("Proxy running on %v, relaying to %v.", l.Addr(), p.conf.Upstream)
+	return nil
+}
+
+// Addr returns the address that the Proxy listens on. Addr returns nil if Listen was not yet called.
+func (p *Proxy) Addr() net.Addr {
+	if p.l == nil {
+		return nil
+	}
+	return p.l.Addr()
+}
+
+// Serve accepts clients and relays them to the upstream server until the Proxy is closed. Listen must be called
+// before Serve. Serve returns once all connections have been closed. The error returned is nil if the Proxy was
+// closed using Close, or the error that caused the listener to stop accepting clients otherwise.
+func (p *Proxy) Serve() error {
+	defer p.wg.Wait()
+	for {
+		c, err := p.l.Accept()
+		if err != nil {
+			if p.closed.Load() {
+				return nil
+			}
+			return err
+		}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.handle(c.(*minecraft.Conn))
+		}()
+	}
+}
+
+// Close closes the Proxy, disconnecting all clients and stopping Serve.
+func (p *Proxy) Close() error {
+	var err error
+	p.once.Do(func() {
+		p.closed.Store(true)
+		if p.l != nil {
+			err = p.l.Close()
+		}
+	})
+	return err
+}
+
+// handle connects a client to the upstream server and relays packets between the two until either of them
+// disconnects.
+func (p *Proxy) handle(conn *minecraft.Conn) {
+	defer conn.Close()
+
+	d := p.conf.Dialer
+	d.IdentityData, d.ClientData = conn.IdentityData(), conn.ClientData()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	serverConn, err := d.DialContext(ctx, p.conf.UpstreamNetwork, p.conf.Upstream)
+	if err != nil {
+		p.conf.Log.Errorf("proxy: connect %v to upstream: %v", conn.IdentityData().DisplayName, err)
+		_ = p.l.Disconnect(conn, "Could not connect to the server.")
+		return
+	}
+	defer serverConn.Close()
+
+	var g sync.WaitGroup
+	g.Add(2)
+	errs := make(chan error, 2)
+	go func() {
+		defer g.Done()
+		errs <- conn.StartGameContext(ctx, serverConn.GameData())
+	}()
+	go func() {
+		defer g.Done()
+		errs <- serverConn.DoSpawnContext(ctx)
+	}()
+	g.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			p.conf.Log.Errorf("proxy: spawn %v: %v", conn.IdentityData().DisplayName, err)
+			return
+		}
+	}
+	p.conf.Log.Debugf("proxy: %v (%v) joined", conn.IdentityData().DisplayName, conn.Protocol().Ver())
+
+	go func() {
+		defer serverConn.Close()
+		for {
+			pk, err := conn.ReadPacket()
+			if err != nil {
+				return
+			}
+			if err := serverConn.WritePacket(pk); err != nil {
+				p.disconnect(conn, err)
+				return
+			}
+		}
+	}()
+	for {
+		pk, err := serverConn.ReadPacket()
+		if err != nil {
+			p.disconnect(conn, err)
+			return
+		}
+		if err := conn.WritePacket(pk); err != nil {
+			return
+		}
+	}
+}
+
+// disconnect disconnects a client after its connection to the upstream server was closed with the error passed.
+// If the upstream server disconnected the client with a message, the client is shown the same message.
+func (p *Proxy) disconnect(conn *minecraft.Conn, err error) {
+	var disc minecraft.DisconnectError
+	if errors.As(err, &disc) {
+		_ = p.l.Disconnect(conn, disc.Error())
+	}
+}
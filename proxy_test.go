@@ -0,0 +1,110 @@
+package vers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestProxy tests relaying clients of every supported protocol to a local upstream server on the latest protocol.
+func TestProxy(t *testing.T) {
+	upstream, err := minecraft.ListenConfig{AuthenticationDisabled: true}.Listen(verstest.Network, ":0")
+	if err != nil {
+		t.Fatalf("listen upstream: %v", err)
+	}
+	defer upstream.Close()
+	go serveEcho(upstream)
+
+	v := New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...), WithListenConfig(minecraft.ListenConfig{AuthenticationDisabled: true}))
+	p := NewProxy(v, ProxyConfig{
+		Upstream:        upstream.Addr().String(),
+		UpstreamNetwork: verstest.Network,
+		Log:             newConfig().Log,
+	})
+	if err := p.Listen(); err != nil {
+		t.Fatalf("listen proxy: %v", err)
+	}
+	defer p.Close()
+	go p.Serve()
+
+	for _, proto := range protocols {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			client, err := verstest.Dial(p.Addr().String(), proto)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			defer client.Close()
+
+			if client.GameData().WorldName != "Upstream" {
+				t.Errorf("expected world name %q, got %q", "Upstream", client.GameData().WorldName)
+			}
+			if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: proto.Ver()}); err != nil {
+				t.Fatalf("write packet: %v", err)
+			}
+			if err := expectText(client, "echo: "+proto.Ver()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestProxyServe tests that Serve returns nil when the Proxy is closed, and an error when its listener stops
+// accepting clients for another reason.
+func TestProxyServe(t *testing.T) {
+	for _, closeProxy := range []bool{true, false} {
+		p := NewProxy(New(":0", WithNetwork(verstest.Network)), ProxyConfig{Log: newConfig().Log})
+		if err := p.Listen(); err != nil {
+			t.Fatalf("listen proxy: %v", err)
+		}
+		errs := make(chan error, 1)
+		go func() {
+			errs <- p.Serve()
+		}()
+		if closeProxy {
+			_ = p.Close()
+		} else {
+			_ = p.l.Close()
+		}
+
+		select {
+		case err := <-errs:
+			if closeProxy && err != nil {
+				t.Errorf("expected nil error after Close, got %v", err)
+			} else if !closeProxy && err == nil {
+				t.Error("expected error after listener was closed")
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal("Serve did not return")
+		}
+		_ = p.Close()
+	}
+}
+
+// serveEcho accepts connections on the listener passed and replies to every Text packet with a Text packet
+// holding the same message prefixed with "echo: ".
+func serveEcho(l *minecraft.Listener) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn *minecraft.Conn) {
+			defer conn.Close()
+			if err := conn.StartGame(minecraft.GameData{WorldName: "Upstream", EntityUniqueID: 1, EntityRuntimeID: 1}); err != nil {
+				return
+			}
+			for {
+				pk, err := conn.ReadPacket()
+				if err != nil {
+					return
+				}
+				if text, ok := pk.(*packet.Text); ok {
+					_ = conn.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: "echo: " + text.Message})
+				}
+			}
+		}(c.(*minecraft.Conn))
+	}
+}
@@ -0,0 +1,130 @@
+package vers
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// versionPacks holds resource packs that are only sent to clients with a protocol ID between min and max.
+type versionPacks struct {
+	min, max int32
+	packs    []*resource.Pack
+}
+
+// packKey returns the key that the client uses to refer to a resource pack, which is made up of its UUID and
+// version.
+func packKey(pack *resource.Pack) string {
+	return pack.UUID() + "_" + pack.Version()
+}
+
+// allPacks returns all resource packs of the versionPacks passed, without duplicates. The listener holds all of
+// these, so that any of them may be downloaded by clients that the pack was announced to.
+func allPacks(vps []versionPacks) []*resource.Pack {
+	var (
+		packs []*resource.Pack
+		seen  = map[string]struct{}{}
+	)
+	for _, vp := range vps {
+		for _, pack := range vp.packs {
+			if _, ok := seen[packKey(pack)]; !ok {
+				seen[packKey(pack)] = struct{}{}
+				packs = append(packs, pack)
+			}
+		}
+	}
+	return packs
+}
+
+// selectPacks returns a minecraft.Protocol that translates packets using the protocol passed, hiding all
+// resource packs of the versionPacks passed that are not meant for the protocol and announcing the URLs passed,
+// keyed by pack key, for the packs that are downloaded from them.
+func selectPacks(proto minecraft.Protocol, vps []versionPacks, urls map[string]string) minecraft.Protocol {
+	hidden, shown := map[string]struct{}{}, map[string]struct{}{}
+	for _, vp := range vps {
+		m := hidden
+		if proto.ID() >= vp.min && proto.ID() <= vp.max {
+			m = shown
+		}
+		for _, pack := range vp.packs {
+			m[packKey(pack)] = struct{}{}
+		}
+	}
+	for key := range shown {
+		delete(hidden, key)
+	}
+	if len(hidden) == 0 && len(urls) == 0 {
+		return proto
+	}
+	return packProtocol{Protocol: proto, hidden: hidden, urls: urls}
+}
+
+// packProtocol is a minecraft.Protocol that changes the packets of the resource pack handshake. The listener
+// holds the same resource packs for every connection, so packs meant for other protocols are removed from the
+// packets that announce them instead. Packs added using WithPackURLs are announced with their URL, which clients
+// that do not support pack URLs drop when the packet is translated, so that these download the pack in-band.
+type packProtocol struct {
+	minecraft.Protocol
+	hidden map[string]struct{}
+	urls   map[string]string
+}
+
+// ConvertFromLatest ...
+func (p packProtocol) ConvertFromLatest(pk packet.Packet, conn *minecraft.Conn) []packet.Packet {
+	switch pk := pk.(type) {
+	case *packet.ResourcePacksInfo:
+		info := *pk
+		info.HasScripts = false
+		info.TexturePacks, info.BehaviourPacks, info.PackURLs = nil, nil, nil
+		for _, pack := range pk.TexturePacks {
+			if !p.isHidden(pack.UUID, pack.Version) {
+				info.TexturePacks = append(info.TexturePacks, pack)
+			}
+		}
+		for _, pack := range pk.BehaviourPacks {
+			if !p.isHidden(pack.UUID, pack.Version) {
+				info.BehaviourPacks = append(info.BehaviourPacks, pack)
+				info.HasScripts = info.HasScripts || pack.HasScripts
+			}
+		}
+		for _, url := range pk.PackURLs {
+			if _, ok := p.hidden[url.UUIDVersion]; !ok {
+				info.PackURLs = append(info.PackURLs, url)
+			}
+		}
+		for _, pack := range info.TexturePacks {
+			if url, ok := p.urls[pack.UUID+"_"+pack.Version]; ok {
+				info.PackURLs = append(info.PackURLs, protocol.PackURL{UUIDVersion: pack.UUID + "_" + pack.Version, URL: url})
+			}
+		}
+		for _, pack := range info.BehaviourPacks {
+			if url, ok := p.urls[pack.UUID+"_"+pack.Version]; ok {
+				info.PackURLs = append(info.PackURLs, protocol.PackURL{UUIDVersion: pack.UUID + "_" + pack.Version, URL: url})
+			}
+		}
+		return p.Protocol.ConvertFromLatest(&info, conn)
+	case *packet.ResourcePackStack:
+		stack := *pk
+		stack.TexturePacks, stack.BehaviourPacks = p.filterStack(pk.TexturePacks), p.filterStack(pk.BehaviourPacks)
+		return p.Protocol.ConvertFromLatest(&stack, conn)
+	}
+	return p.Protocol.ConvertFromLatest(pk, conn)
+}
+
+// filterStack returns the resource packs of a stack that are not hidden.
+func (p packProtocol) filterStack(packs []protocol.StackResourcePack) []protocol.StackResourcePack {
+	var filtered []protocol.StackResourcePack
+	for _, pack := range packs {
+		if !p.isHidden(pack.UUID, pack.Version) {
+			filtered = append(filtered, pack)
+		}
+	}
+	return filtered
+}
+
+// isHidden checks if the resource pack with the UUID and version passed is hidden from the protocol.
+func (p packProtocol) isHidden(uuid, version string) bool {
+	_, ok := p.hidden[uuid+"_"+version]
+	return ok
+}
@@ -0,0 +1,92 @@
+package vers
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oomph-ac/mv/multiversion/mv649"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/resource"
+)
+
+// TestResourcePacks tests that clients are only sent the resource packs registered for their protocol, in
+// addition to the resource packs of the server config.
+func TestResourcePacks(t *testing.T) {
+	shared, old, current := testPack(t, "shared"), testPack(t, "old"), testPack(t, "current")
+
+	conf := newConfig()
+	conf.Resources = []*resource.Pack{shared}
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv649.Protocol{}, mv671.Protocol{}),
+		WithResourcePacks(mv649.Protocol{}.ID(), mv649.Protocol{}.ID(), old),
+		WithResourcePacks(mv671.Protocol{}.ID(), minecraft.DefaultProtocol.ID(), current),
+	).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	for _, tc := range []struct {
+		proto minecraft.Protocol
+		want  []*resource.Pack
+	}{
+		{mv649.Protocol{}, []*resource.Pack{shared, old}},
+		{mv671.Protocol{}, []*resource.Pack{shared, current}},
+		{minecraft.DefaultProtocol, []*resource.Pack{shared, current}},
+	} {
+		t.Run(tc.proto.Ver(), func(t *testing.T) {
+			client, conn, err := join(l, tc.proto)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			defer conn.Close()
+
+			got := map[string]bool{}
+			for _, pack := range client.ResourcePacks() {
+				got[pack.Name()] = true
+			}
+			if len(got) != len(tc.want) {
+				t.Errorf("expected %v resource packs, got %v", len(tc.want), client.ResourcePacks())
+			}
+			for _, pack := range tc.want {
+				if !got[pack.Name()] {
+					t.Errorf("expected resource pack %v to be sent", pack.Name())
+				}
+			}
+		})
+	}
+}
+
+// testPack returns a resource pack holding nothing but a manifest with the name passed.
+func testPack(t *testing.T, name string) *resource.Pack {
+	return testPackManifest(t, fmt.Sprintf(`{
+	"format_version": 2,
+	"header": {"name": %q, "description": "", "uuid": %q, "version": [1, 0, 0], "min_engine_version": [1, 20, 0]},
+	"modules": [{"type": "resources", "uuid": %q, "version": [1, 0, 0]}]
+}`, name, uuid.New(), uuid.New()))
+}
+
+// testPackManifest returns a resource pack holding nothing but the manifest passed.
+func testPackManifest(t *testing.T, manifest string) *resource.Pack {
+	buf := bytes.NewBuffer(nil)
+	w := zip.NewWriter(buf)
+	f, err := w.Create("manifest.json")
+	if err != nil {
+		t.Fatalf("create manifest: %v", err)
+	}
+	_, _ = f.Write([]byte(manifest))
+	if err := w.Close(); err != nil {
+		t.Fatalf("close archive: %v", err)
+	}
+	pack, err := resource.Read(buf)
+	if err != nil {
+		t.Fatalf("read pack: %v", err)
+	}
+	return pack
+}
@@ -0,0 +1,237 @@
+package vers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oomph-ac/mv/multiversion"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// UnsupportedPolicy decides what happens to a client that joins with a protocol that the listener does not
+// accept. It is passed the protocol ID of the client and the protocols that are accepted, and returns the packet
+// sent to the client in response to the first packet it sends, after which it is disconnected. This is typically
+// a *packet.Disconnect to show a message or a *packet.Transfer to send the client to another server. If nil is
+// returned, the client is shown the default outdated client or outdated server screen. Policies only apply to
+// clients of 1.19.30 or newer, as older clients send their protocol ID after compression is enabled, and only
+// on the RakNet network.
+type UnsupportedPolicy func(id int32, accepted []minecraft.Protocol) packet.Packet
+
+// DisconnectUnsupported returns an UnsupportedPolicy that disconnects clients with the message passed, followed
+// by the range of versions that the listener accepts, such as "Supported versions: 1.20.0 - 1.21.0".
+func DisconnectUnsupported(message string) UnsupportedPolicy {
+	return func(_ int32, accepted []minecraft.Protocol) packet.Packet {
+		return &packet.Disconnect{Message: message + "\n" + SupportedVersions(accepted)}
+	}
+}
+
+// TransferUnsupported returns an UnsupportedPolicy that transfers clients to the server at the address passed,
+// which may, for example, run the version of the client. The Transfer packet is sent before the client logs in,
+// so clients that do not follow transfers at that point are disconnected without being transferred.
+func TransferUnsupported(address string, port uint16) UnsupportedPolicy {
+	return func(int32, []minecraft.Protocol) packet.Packet {
+		return &packet.Transfer{Address: address, Port: port}
+	}
+}
+
+// SupportedVersions returns a message holding the range of versions of the protocols passed, such as "Supported
+// versions: 1.20.0 - 1.21.0".
+func SupportedVersions(protocols []minecraft.Protocol) string {
+	if len(protocols) == 0 {
+		return "Supported version: " + minecraft.DefaultProtocol.Ver()
+	}
+	oldest := slices.MinFunc(protocols, compareProtocols)
+	newest := slices.MaxFunc(protocols, compareProtocols)
+	if oldest.ID() == newest.ID() {
+		return "Supported version: " + oldest.Ver()
+	}
+	return fmt.Sprintf("Supported versions: %v - %v", oldest.Ver(), newest.Ver())
+}
+
+// compareProtocols compares two protocols by their ID.
+func compareProtocols(a, b minecraft.Protocol) int {
+	return int(a.ID() - b.ID())
+}
+
+// minUnsupportedProtocol is the protocol ID of 1.19.30, the first version in which clients send their protocol
+// ID in a RequestNetworkSettings packet before compression is enabled. Older clients send it in the Login packet,
+// when compression is already enabled, so an UnsupportedPolicy cannot apply to them.
+const minUnsupportedProtocol = 554
+
+// rejection returns the packet sent to a client joining with the protocol ID passed, or nil if the protocol is in
+// the accepted protocols passed or no UnsupportedPolicy applies to it. The default policy applies to protocol IDs
+// from minUnsupportedProtocol up to the current protocol, as the packets of newer protocols are not known.
+func (v *Vers) rejection(id int32, accepted []minecraft.Protocol) packet.Packet {
+	if id < minUnsupportedProtocol || slices.ContainsFunc(accepted, func(proto minecraft.Protocol) bool {
+		return proto.ID() == id
+	}) {
+		return nil
+	}
+	policy, ok := v.unsupported[id]
+	if !ok {
+		if v.unsupportedPolicy == nil || id > protocol.CurrentProtocol {
+			return nil
+		}
+		policy = v.unsupportedPolicy
+	}
+	return policy(id, accepted)
+}
+
+// rejectUnsupported returns a function to use as the PacketFunc of a listener, which rejects clients joining with
+// a protocol that is not in the accepted protocols passed using the UnsupportedPolicy that applies to it. The
+// function f is called for every packet first if it is not nil. Clients are only rejected if the listener is on
+// an unsupportedNetwork, as the packet is written to the connection before the listener handles the packet.
+func (v *Vers) rejectUnsupported(accepted []minecraft.Protocol, f func(header packet.Header, payload []byte, src, dst net.Addr)) func(header packet.Header, payload []byte, src, dst net.Addr) {
+	var (
+		protocols []minecraft.Protocol
+		ids       = map[int32]struct{}{}
+	)
+	for _, proto := range append(slices.Clone(accepted), minecraft.DefaultProtocol) {
+		if _, ok := ids[proto.ID()]; !ok {
+			ids[proto.ID()] = struct{}{}
+			protocols = append(protocols, unwrap(proto))
+		}
+	}
+	return func(header packet.Header, payload []byte, src, dst net.Addr) {
+		if f != nil {
+			f(header, payload, src, dst)
+		}
+		if header.PacketID != packet.IDRequestNetworkSettings && header.PacketID != packet.IDLogin {
+			return
+		}
+		l, ok := unsupportedListeners.Load(dst.String())
+		if !ok {
+			return
+		}
+		// Only the first packet of a connection holds the protocol ID of the client.
+		conn, ok := l.(*unsupportedListener).take(src)
+		if !ok || header.PacketID != packet.IDRequestNetworkSettings || len(payload) < 4 {
+			return
+		}
+		id := int32(binary.BigEndian.Uint32(payload))
+		if pk := v.rejection(id, protocols); pk != nil {
+			reject(conn, id, pk)
+		}
+	}
+}
+
+// rejectTimeout is the maximum time that reject waits for the connection of a rejected client to be closed.
+const rejectTimeout = time.Second * 10
+
+// reject sends the packet passed to a client joining with the protocol ID passed and closes its connection. The
+// packet is translated and encoded using the built-in protocol nearest to the ID, without compression, as the
+// client has not yet been sent the network settings. reject returns once the connection is closed, so that the
+// listener cannot send the client the default outdated client or outdated server screen afterwards.
+func reject(conn net.Conn, id int32, pk packet.Packet) {
+	proto := nearestProtocol(id)
+	var batch [][]byte
+	for _, pk := range proto.ConvertFromLatest(pk, nil) {
+		buf := bytes.NewBuffer(nil)
+		_ = (&packet.Header{PacketID: pk.ID()}).Write(buf)
+		pk.Marshal(proto.NewWriter(buf, 0))
+		batch = append(batch, buf.Bytes())
+	}
+	_ = packet.NewEncoder(conn).Encode(batch)
+	_ = conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(rejectTimeout))
+	dec := packet.NewDecoder(conn)
+	for {
+		if _, err := dec.Decode(); err != nil {
+			return
+		}
+	}
+}
+
+// nearestProtocol returns the newest built-in protocol with an ID lower than or equal to the ID passed, or the
+// oldest built-in protocol if the ID is lower than that of all built-in protocols. Packets sent to clients
+// joining with a protocol that is not accepted are encoded using this protocol.
+func nearestProtocol(id int32) minecraft.Protocol {
+	known := multiversion.Protocols()
+	nearest := known[0]
+	for _, proto := range known {
+		if proto.ID() <= id {
+			nearest = proto
+		}
+	}
+	return nearest
+}
+
+// unsupportedNetwork is a minecraft.Network of which the listeners keep track of the connections they accepted
+// until the first packet of the connection is read, so that the client may be rejected by an UnsupportedPolicy.
+type unsupportedNetwork struct {
+	minecraft.Network
+}
+
+// Listen ...
+func (n unsupportedNetwork) Listen(address string) (minecraft.NetworkListener, error) {
+	l, err := n.Network.Listen(address)
+	if err != nil {
+		return nil, err
+	}
+	ul := &unsupportedListener{NetworkListener: l, conns: map[string]pendingConn{}}
+	unsupportedListeners.Store(l.Addr().String(), ul)
+	return ul, nil
+}
+
+// unsupportedListeners holds the open listeners of unsupportedNetworks, keyed by their address.
+var unsupportedListeners sync.Map
+
+// pendingTimeout is the time after which an unsupportedListener forgets a connection of which no packet was
+// read. Clients send their first packet right after connecting, so this only happens if the client left.
+const pendingTimeout = time.Minute
+
+// unsupportedListener is a minecraft.NetworkListener that keeps track of the connections it accepted, keyed by
+// their remote address, until they are taken by rejectUnsupported.
+type unsupportedListener struct {
+	minecraft.NetworkListener
+
+	mu    sync.Mutex
+	conns map[string]pendingConn
+}
+
+// pendingConn is a connection accepted by an unsupportedListener of which no packet was read yet.
+type pendingConn struct {
+	net.Conn
+	accepted time.Time
+}
+
+// Accept ...
+func (l *unsupportedListener) Accept() (net.Conn, error) {
+	conn, err := l.NetworkListener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for addr, pending := range l.conns {
+		if time.Since(pending.accepted) > pendingTimeout {
+			delete(l.conns, addr)
+		}
+	}
+	l.conns[conn.RemoteAddr().String()] = pendingConn{Conn: conn, accepted: time.Now()}
+	return conn, nil
+}
+
+// take returns the connection with the remote address passed and forgets it. False is returned if the listener
+// did not accept the connection or if it was already taken.
+func (l *unsupportedListener) take(addr net.Addr) (net.Conn, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending, ok := l.conns[addr.String()]
+	delete(l.conns, addr.String())
+	return pending.Conn, ok
+}
+
+// Close ...
+func (l *unsupportedListener) Close() error {
+	unsupportedListeners.CompareAndDelete(l.Addr().String(), l)
+	return l.NetworkListener.Close()
+}
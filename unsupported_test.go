@@ -0,0 +1,108 @@
+package vers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oomph-ac/mv/multiversion/mv589"
+	"github.com/oomph-ac/mv/multiversion/mv594"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/verstest"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// TestUnsupportedPolicy tests that clients joining with a protocol that is not accepted are handled by the
+// policy set for their protocol ID, and that accepted protocols are never affected.
+func TestUnsupportedPolicy(t *testing.T) {
+	v := New(":0", WithProtocols(mv589.Protocol{}, mv671.Protocol{}),
+		WithUnsupportedPolicy(DisconnectUnsupported("Outdated!")),
+		WithUnsupportedPolicy(TransferUnsupported("old.example.com", 19132), 575),
+		WithUnsupportedPolicy(func(int32, []minecraft.Protocol) packet.Packet { return nil }, 560, 700),
+	)
+	accepted := []minecraft.Protocol{mv589.Protocol{}, mv671.Protocol{}, minecraft.DefaultProtocol}
+	for _, id := range []int32{589, 671, protocol.CurrentProtocol, minUnsupportedProtocol - 1, protocol.CurrentProtocol + 1, 560, 700} {
+		if pk := v.rejection(id, accepted); pk != nil {
+			t.Errorf("expected protocol %v not to be rejected, got %#v", id, pk)
+		}
+	}
+	if pk, ok := v.rejection(600, accepted).(*packet.Disconnect); !ok || !strings.HasPrefix(pk.Message, "Outdated!\n") || !strings.Contains(pk.Message, "1.20.0 - 1.21.0") {
+		t.Errorf("expected disconnect with supported versions, got %#v", pk)
+	}
+	if pk, ok := v.rejection(575, accepted).(*packet.Transfer); !ok || pk.Address != "old.example.com" || pk.Port != 19132 {
+		t.Errorf("expected transfer to old.example.com:19132, got %#v", pk)
+	}
+	if ver := nearestProtocol(600).Ver(); ver != (mv594.Protocol{}).Ver() {
+		t.Errorf("expected packets of protocol 600 to be encoded as %v, got %v", (mv594.Protocol{}).Ver(), ver)
+	}
+}
+
+// TestUnsupportedJoin tests that clients joining with a protocol that is not accepted receive only the packet
+// returned by the UnsupportedPolicy before they are disconnected, and that clients of accepted protocols and
+// protocols without a policy are handled by the listener as usual.
+func TestUnsupportedJoin(t *testing.T) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(mv589.Protocol{}),
+		WithUnsupportedPolicy(DisconnectUnsupported("Outdated!")),
+		WithUnsupportedPolicy(TransferUnsupported("old.example.com", 19132), 575),
+	).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+
+	packets, err := verstest.Rejected(l.(listener).Addr().String(), protocolID{Protocol: mv594.Protocol{}, id: 600})
+	if err != nil {
+		t.Fatalf("join 600: %v", err)
+	}
+	if len(packets) != 1 {
+		t.Fatalf("expected a single packet, got %v", packets)
+	}
+	if pk, ok := packets[0].(*packet.Disconnect); !ok || !strings.HasPrefix(pk.Message, "Outdated!\n") {
+		t.Errorf("expected disconnect, got %#v", packets[0])
+	}
+
+	packets, err = verstest.Rejected(l.(listener).Addr().String(), protocolID{Protocol: mv589.Protocol{}, id: 575})
+	if err != nil {
+		t.Fatalf("join 575: %v", err)
+	}
+	if len(packets) != 1 {
+		t.Fatalf("expected a single packet, got %v", packets)
+	}
+	if pk, ok := packets[0].(*packet.Transfer); !ok || pk.Address != "old.example.com" || pk.Port != 19132 {
+		t.Errorf("expected transfer to old.example.com:19132, got %#v", packets[0])
+	}
+
+	if accepted, err := verstest.Accepts(l.(listener).Addr().String(), mv589.Protocol{}); err != nil || !accepted {
+		t.Errorf("expected accepted protocol to be accepted: accepted=%v, err=%v", accepted, err)
+	}
+	packets, err = verstest.Rejected(l.(listener).Addr().String(), protocolID{Protocol: minecraft.DefaultProtocol, id: protocol.CurrentProtocol + 1})
+	if err != nil {
+		t.Fatalf("join %v: %v", protocol.CurrentProtocol+1, err)
+	}
+	if len(packets) != 1 {
+		t.Fatalf("expected a single packet, got %v", packets)
+	}
+	if pk, ok := packets[0].(*packet.PlayStatus); !ok || pk.Status != packet.PlayStatusLoginFailedServer {
+		t.Errorf("expected outdated server status, got %#v", packets[0])
+	}
+}
+
+// init makes the in-memory network known to Vers, so that UnsupportedPolicies apply to listeners on it.
+func init() {
+	knownNetworks[verstest.Network] = verstest.Net
+}
+
+// protocolID is a minecraft.Protocol with a different protocol ID, used to join as a client of a version without
+// a built-in protocol.
+type protocolID struct {
+	minecraft.Protocol
+	id int32
+}
+
+// ID ...
+func (p protocolID) ID() int32 {
+	return p.id
+}
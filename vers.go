@@ -1,40 +1,133 @@
 package vers
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/df-mc/dragonfly/server"
+	"github.com/oomph-ac/mv/multiversion/latest"
 	"github.com/sandertv/gophertunnel/minecraft"
 )
 
 // Vers is an instance for binding a multi-version Dragonfly server.
 type Vers struct {
-	addr string
+	addr      string
+	network   string
+	protocols []minecraft.Protocol
+	conf      minecraft.ListenConfig
+
+	interceptors []Interceptor
+	packs        []versionPacks
+	downgrade    bool
+	packReport   func(PackReport)
+	packURLs     []packURL
+
+	unsupported       map[int32]UnsupportedPolicy
+	unsupportedPolicy UnsupportedPolicy
+}
+
+// New creates a new Vers instance. Options may be passed to change the way Vers listens for connections.
+func New(localAddr string, opts ...Option) *Vers {
+	v := &Vers{
+		addr:    localAddr,
+		network: "raknet",
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
-// New creates a new Vers instance.
-func New(localAddr string) *Vers {
-	return &Vers{
-		addr: localAddr,
+// Bind replaces the listeners of the server.Config passed with a listener on the address of the Vers instance
+// that accepts all protocols passed to it.
+func (v *Vers) Bind(conf *server.Config) {
+	BindAll(conf, v)
+}
+
+// BindAll replaces the listeners of the server.Config passed with a listener for each of the Vers instances
+// passed. This may be used to bind multiple addresses, for example an IPv4 and an IPv6 address, or a public and
+// an internal address, each accepting its own set of protocols.
+func BindAll(conf *server.Config, vers ...*Vers) {
+	conf.Listeners = make([]func(conf server.Config) (server.Listener, error), 0, len(vers))
+	for _, v := range vers {
+		conf.Listeners = append(conf.Listeners, v.listen)
 	}
 }
 
-// Listen listens for incoming connections on the address.
+// Listen replaces the listeners of the server.Config passed with a listener on the address of the Vers instance
+// that accepts the protocols passed, using name in the server list.
+//
+// Deprecated: Pass the protocols using WithProtocols and the other settings using WithListenConfig to New, and
+// call Bind instead.
 func (v *Vers) Listen(conf *server.Config, name string, protocols []minecraft.Protocol, requirePacks bool) {
-	conf.Listeners = nil
-	conf.Listeners = append(conf.Listeners, func(_ server.Config) (server.Listener, error) {
-		l, err := minecraft.ListenConfig{
-			StatusProvider:       minecraft.NewStatusProvider(name),
-			ResourcePacks:        conf.Resources,
-			TexturePacksRequired: requirePacks,
-			AcceptedProtocols:    protocols,
-		}.Listen("raknet", v.addr)
-		if err != nil {
-			return nil, err
-		}
+	v.conf.StatusProvider = minecraft.NewStatusProvider(name, "Dragonfly")
+	v.conf.TexturePacksRequired = requirePacks
+	v.protocols = append(v.protocols, protocols...)
+	v.Bind(conf)
+}
+
+// listen creates a multi-version listener for the server.Config passed.
+func (v *Vers) listen(conf server.Config) (server.Listener, error) {
+	cfg, err := v.listenConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	l, err := cfg.Listen(v.listenNetwork(cfg.AcceptedProtocols), v.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.Log.Infof("Server running on %v.", l.Addr())
 
-		conf.Log.Infof("Server running on %v.", l.Addr())
+	return listener{
+		Listener: l,
+		conns:    newConnections(),
+	}, nil
+}
 
-		return listener{
-			Listener: l,
-		}, nil
-	})
+// listenConfig returns the minecraft.ListenConfig used to listen for connections. Fields not set using
+// WithListenConfig are filled in from the server.Config passed. An error is returned if the block and item tables
+// of the latest version could not be built, or if the resource packs could not be loaded or downgraded.
+func (v *Vers) listenConfig(conf server.Config) (minecraft.ListenConfig, error) {
+	cfg := v.conf
+	if err := latest.Load(); err != nil {
+		return cfg, fmt.Errorf("load block and item tables: %w", err)
+	}
+	cfg.AcceptedProtocols = append(slices.Clone(cfg.AcceptedProtocols), v.protocols...)
+	if cfg.ResourcePacks == nil {
+		cfg.ResourcePacks = conf.Resources
+	}
+	urlPacks, urls, err := loadPackURLs(v.packURLs)
+	if err != nil {
+		return cfg, err
+	}
+	packs := append(slices.Clone(v.packs), urlPacks...)
+	if v.downgrade && len(cfg.ResourcePacks) > 0 {
+		downgraded, err := v.downgradePacks(cfg.ResourcePacks, append(slices.Clone(cfg.AcceptedProtocols), minecraft.DefaultProtocol))
+		if err != nil {
+			return cfg, fmt.Errorf("downgrade resource packs: %w", err)
+		}
+		packs, cfg.ResourcePacks = append(packs, downgraded...), nil
+	}
+	if len(v.interceptors) > 0 || len(packs) > 0 {
+		// The latest protocol is always accepted by the listener, but it must be added explicitly for its packets
+		// to be intercepted and its resource packs to be selected as well.
+		cfg.AcceptedProtocols = append(cfg.AcceptedProtocols, minecraft.DefaultProtocol)
+		for i, proto := range cfg.AcceptedProtocols {
+			cfg.AcceptedProtocols[i] = Intercept(selectPacks(proto, packs, urls), v.interceptors...)
+		}
+	}
+	if len(v.unsupported) > 0 || v.unsupportedPolicy != nil {
+		cfg.PacketFunc = v.rejectUnsupported(cfg.AcceptedProtocols, cfg.PacketFunc)
+	}
+	cfg.ResourcePacks = append(slices.Clone(cfg.ResourcePacks), allPacks(packs)...)
+	if cfg.StatusProvider == nil {
+		cfg.StatusProvider = minecraft.NewStatusProvider(conf.Name, "Dragonfly")
+	}
+	if cfg.MaximumPlayers == 0 {
+		cfg.MaximumPlayers = conf.MaxPlayers
+	}
+	cfg.AuthenticationDisabled = cfg.AuthenticationDisabled || conf.AuthDisabled
+	cfg.TexturePacksRequired = cfg.TexturePacksRequired || conf.ResourcesRequired
+	return cfg, nil
 }
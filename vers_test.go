@@ -2,64 +2,240 @@ package vers
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/df-mc/dragonfly/server"
-	"github.com/df-mc/dragonfly/server/player/chat"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/oomph-ac/mv/multiversion"
 	"github.com/oomph-ac/mv/multiversion/mv618"
-	"github.com/pelletier/go-toml"
+	"github.com/oomph-ac/mv/multiversion/mv662"
+	"github.com/oomph-ac/mv/multiversion/mv671"
+	"github.com/oomph-ac/mv/verstest"
 	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
 	"github.com/sirupsen/logrus"
 )
 
-// TestVers tests the Vers multi-version support for Dragonfly.
-func TestVers(t *testing.T) {
-	log := logrus.New()
-	log.Formatter = &logrus.TextFormatter{ForceColors: true}
-	log.Level = logrus.DebugLevel
+// protocols holds every protocol that clients join with in TestJoin.
+var protocols = multiversion.Legacy()
+
+// TestJoin tests a full join of a client for every supported protocol over the in-memory network, after which
+// packets are exchanged in both directions.
+func TestJoin(t *testing.T) {
+	for _, proto := range protocols {
+		t.Run(proto.Ver(), func(t *testing.T) {
+			testJoin(t, proto)
+		})
+	}
+}
 
-	chat.Global.Subscribe(chat.StdoutSubscriber{})
+// testJoin tests a full join of a client using the protocol passed.
+func testJoin(t *testing.T, proto minecraft.Protocol) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...)).Bind(conf)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
 
-	cfg, err := readConfig(log)
+	client, conn, err := join(l, proto)
 	if err != nil {
-		log.Fatalln(err)
+		t.Fatal(err)
+	}
+	defer client.Close()
+	defer conn.Close()
+
+	if client.GameData().WorldName != "World" {
+		t.Errorf("expected world name %q, got %q", "World", client.GameData().WorldName)
 	}
 
-	v := New(":6900")
-	v.Listen(&cfg, cfg.Name, []minecraft.Protocol{
-		mv618.Protocol{},
-	}, false)
+	if err := client.WritePacket(&packet.Text{TextType: packet.TextTypeChat, SourceName: "Steve", Message: "Hello, server!"}); err != nil {
+		t.Fatalf("write to server: %v", err)
+	}
+	if err := expectText(conn.Conn, "Hello, server!"); err != nil {
+		t.Fatalf("server: %v", err)
+	}
+	if err := conn.WritePacket(&packet.Text{TextType: packet.TextTypeRaw, Message: "Hello, client!"}); err != nil {
+		t.Fatalf("write to client: %v", err)
+	}
+	if err := expectText(client, "Hello, client!"); err != nil {
+		t.Fatalf("client: %v", err)
+	}
+}
 
-	srv := cfg.New()
-	srv.CloseOnProgramEnd()
-	srv.Listen()
+// TestDeprecatedListen tests that the deprecated Listen method still accepts the protocols passed to it.
+func TestDeprecatedListen(t *testing.T) {
+	conf := newConfig()
+	New(":0", WithNetwork(verstest.Network)).Listen(conf, "Server", []minecraft.Protocol{mv671.Protocol{}}, false)
+	l, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
 
-	for srv.Accept(nil) {
+	client, conn, err := join(l, mv671.Protocol{})
+	if err != nil {
+		t.Fatal(err)
 	}
+	_ = client.Close()
+	_ = conn.Close()
 }
 
-// readConfig reads the configuration from the config.toml file, or creates the
-// file if it does not yet exist.
-func readConfig(log server.Logger) (server.Config, error) {
-	c := server.DefaultConfig()
-	var zero server.Config
-	if _, err := os.Stat("config.toml"); os.IsNotExist(err) {
-		data, err := toml.Marshal(c)
+// TestMultipleAddresses tests listening on multiple addresses, each accepting a different set of protocols.
+func TestMultipleAddresses(t *testing.T) {
+	conf := newConfig()
+	BindAll(conf,
+		New("[::]:0", WithNetwork(verstest.Network)),
+		New(":0", WithNetwork(verstest.Network), WithProtocols(protocols...)),
+	)
+	if len(conf.Listeners) != 2 {
+		t.Fatalf("expected 2 listeners, got %v", len(conf.Listeners))
+	}
+	internal, err := conf.Listeners[0](*conf)
+	if err != nil {
+		t.Fatalf("listen internal: %v", err)
+	}
+	defer internal.Close()
+	public, err := conf.Listeners[1](*conf)
+	if err != nil {
+		t.Fatalf("listen public: %v", err)
+	}
+	defer public.Close()
+
+	if addr := internal.(listener).Addr().(*net.UDPAddr); addr.IP.To4() != nil {
+		t.Errorf("expected internal listener to be bound to an IPv6 address, got %v", addr)
+	}
+
+	if accepted, err := verstest.Accepts(internal.(listener).Addr().String(), mv618.Protocol{}); err != nil || accepted {
+		t.Errorf("expected legacy client to be rejected by internal listener: accepted=%v, err=%v", accepted, err)
+	}
+	if accepted, err := verstest.Accepts(public.(listener).Addr().String(), mv618.Protocol{}); err != nil || !accepted {
+		t.Errorf("expected legacy client to be accepted by public listener: accepted=%v, err=%v", accepted, err)
+	}
+	for _, tc := range []struct {
+		l     server.Listener
+		proto minecraft.Protocol
+	}{{internal, minecraft.DefaultProtocol}, {public, mv618.Protocol{}}, {public, minecraft.DefaultProtocol}} {
+		client, conn, err := join(tc.l, tc.proto)
 		if err != nil {
-			return zero, fmt.Errorf("encode default config: %v", err)
+			t.Errorf("join %v using %v: %v", tc.l.(listener).Addr(), tc.proto.Ver(), err)
+			continue
 		}
-		if err := os.WriteFile("config.toml", data, 0644); err != nil {
-			return zero, fmt.Errorf("create default config: %v", err)
+		_ = client.Close()
+		_ = conn.Close()
+	}
+}
+
+// newConfig returns a server.Config for tests that discards all logs.
+func newConfig() *server.Config {
+	log := logrus.New()
+	log.Out = io.Discard
+	return &server.Config{Log: log, AuthDisabled: true}
+}
+
+// join dials the listener passed using the protocol passed, and spawns the client in a world. The client and
+// server side of the connection are returned.
+func join(l server.Listener, proto minecraft.Protocol) (client *minecraft.Conn, conn *Conn, err error) {
+	accepted := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			accepted <- err
+			return
+		}
+		conn = c.(*Conn)
+		accepted <- conn.StartGame(minecraft.GameData{
+			WorldName:       "World",
+			EntityUniqueID:  1,
+			EntityRuntimeID: 1,
+			PlayerGameMode:  1,
+			PlayerPosition:  mgl32.Vec3{0, 64, 0},
+			BaseGameVersion: "1.20.0",
+		})
+	}()
+
+	client, err = verstest.Dial(l.(listener).Addr().String(), proto)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial: %w", err)
+	}
+	if err := <-accepted; err != nil {
+		_ = client.Close()
+		return nil, nil, fmt.Errorf("accept: %w", err)
+	}
+	return client, conn, nil
+}
+
+// expectText reads packets from the connection passed until a Text packet is read, and checks if it holds the
+// message passed.
+func expectText(conn *minecraft.Conn, message string) error {
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	for {
+		pk, err := conn.ReadPacket()
+		if err != nil {
+			return fmt.Errorf("read packet: %w", err)
+		}
+		if text, ok := pk.(*packet.Text); ok {
+			if text.Message != message {
+				return fmt.Errorf("expected message %q, got %q", message, text.Message)
+			}
+			return nil
+		}
+	}
+}
+
+// TestListenConfig tests that the listen config passed using WithListenConfig is completed with the settings of
+// the server.Config passed to Bind, without overwriting settings that were set explicitly.
+func TestListenConfig(t *testing.T) {
+	conf := server.Config{Name: "Vers", MaxPlayers: 20, AuthDisabled: true, ResourcesRequired: true}
+
+	cfg, err := New(":0", WithProtocols(protocols...)).listenConfig(conf)
+	if err != nil {
+		t.Fatalf("listen config: %v", err)
+	}
+	if cfg.StatusProvider == nil || cfg.MaximumPlayers != 20 || !cfg.AuthenticationDisabled || !cfg.TexturePacksRequired {
+		t.Errorf("expected settings of server config to be used, got %+v", cfg)
+	}
+	if len(cfg.AcceptedProtocols) != len(protocols) {
+		t.Errorf("expected %v accepted protocols, got %v", len(protocols), len(cfg.AcceptedProtocols))
+	}
+
+	cfg, err = New(":0", WithListenConfig(minecraft.ListenConfig{
+		MaximumPlayers:    5,
+		FlushRate:         time.Second,
+		AcceptedProtocols: []minecraft.Protocol{mv671.Protocol{}},
+	}), WithProtocols(mv662.Protocol{})).listenConfig(conf)
+	if err != nil {
+		t.Fatalf("listen config: %v", err)
+	}
+	if cfg.MaximumPlayers != 5 || cfg.FlushRate != time.Second {
+		t.Errorf("expected settings of listen config to be kept, got %+v", cfg)
+	}
+	if len(cfg.AcceptedProtocols) != 2 {
+		t.Errorf("expected 2 accepted protocols, got %v", len(cfg.AcceptedProtocols))
+	}
+
+	cfg, err = New(":0", WithProtocols(protocols...), WithInterceptors(NopInterceptor{})).listenConfig(conf)
+	if err != nil {
+		t.Fatalf("listen config: %v", err)
+	}
+	if len(cfg.AcceptedProtocols) != len(protocols)+1 {
+		t.Errorf("expected latest protocol to be accepted explicitly when intercepting, got %v protocols", len(cfg.AcceptedProtocols))
+	}
+	for _, proto := range cfg.AcceptedProtocols {
+		if _, ok := proto.(interceptedProtocol); !ok {
+			t.Errorf("expected protocol %v to be intercepted", proto.Ver())
 		}
-		return zero, nil
 	}
-	data, err := os.ReadFile("config.toml")
+
+	cfg, err = New(":0", WithProtocols(protocols...), WithResourcePacks(0, 0)).listenConfig(conf)
 	if err != nil {
-		return zero, fmt.Errorf("read config: %v", err)
+		t.Fatalf("listen config: %v", err)
 	}
-	if err := toml.Unmarshal(data, &c); err != nil {
-		return zero, fmt.Errorf("decode config: %v", err)
+	if len(cfg.AcceptedProtocols) != len(protocols)+1 {
+		t.Errorf("expected latest protocol to be accepted explicitly when selecting resource packs, got %v protocols", len(cfg.AcceptedProtocols))
 	}
-	return c.Config(log)
 }
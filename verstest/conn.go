@@ -0,0 +1,162 @@
+package verstest
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// datagram is a single packet written to a packetConn, together with the address it was written from.
+type datagram struct {
+	b    []byte
+	addr net.Addr
+}
+
+// packetConn is an in-memory implementation of net.PacketConn. Datagrams written to it are delivered to the
+// packetConn in the hub bound to the destination address. Like UDP, datagrams written to an address that no
+// packetConn is bound to are dropped. If the packetConn was opened with a remote address, it also implements
+// net.Conn, reading from and writing to the remote address.
+type packetConn struct {
+	addr, remote *net.UDPAddr
+
+	incoming chan datagram
+	closed   chan struct{}
+	once     sync.Once
+
+	mu       sync.Mutex
+	deadline time.Time
+	// deadlineChanged is closed and replaced every time the read deadline is changed, so that pending reads pick
+	// up the new deadline.
+	deadlineChanged chan struct{}
+}
+
+// newPacketConn returns a packetConn bound to the address passed.
+func newPacketConn(addr, remote *net.UDPAddr) *packetConn {
+	return &packetConn{
+		addr:            addr,
+		remote:          remote,
+		incoming:        make(chan datagram, 1024),
+		closed:          make(chan struct{}),
+		deadlineChanged: make(chan struct{}),
+	}
+}
+
+// ReadFrom reads a datagram from the packetConn, blocking until one is available, the packetConn is closed or
+// the read deadline passes.
+func (c *packetConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		c.mu.Lock()
+		deadline, changed := c.deadline, c.deadlineChanged
+		c.mu.Unlock()
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if !deadline.IsZero() {
+			timer = time.NewTimer(time.Until(deadline))
+			timeout = timer.C
+		}
+		n, addr, ok, err := c.read(b, timeout, changed)
+		if timer != nil {
+			timer.Stop()
+		}
+		if ok {
+			return n, addr, err
+		}
+	}
+}
+
+// read waits for a datagram to be read into b. If the read deadline was changed before that, read returns false.
+func (c *packetConn) read(b []byte, timeout <-chan time.Time, changed <-chan struct{}) (int, net.Addr, bool, error) {
+	select {
+	case d := <-c.incoming:
+		return copy(b, d.b), d.addr, true, nil
+	case <-c.closed:
+		return 0, nil, true, c.error("read", net.ErrClosed)
+	case <-timeout:
+		return 0, nil, true, c.error("read", os.ErrDeadlineExceeded)
+	case <-changed:
+		return 0, nil, false, nil
+	}
+}
+
+// WriteTo writes a datagram to the packetConn bound to the address passed.
+func (c *packetConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, c.error("write", net.ErrClosed)
+	default:
+	}
+	dst, ok := conns.lookup(addr)
+	if !ok {
+		return len(b), nil
+	}
+	d := datagram{b: append([]byte(nil), b...), addr: c.addr}
+	select {
+	case dst.incoming <- d:
+	case <-dst.closed:
+	case <-c.closed:
+		return 0, c.error("write", net.ErrClosed)
+	}
+	return len(b), nil
+}
+
+// Read reads a datagram sent to the packetConn. It should only be used if the packetConn has a remote address.
+func (c *packetConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+// Write writes a datagram to the remote address of the packetConn.
+func (c *packetConn) Write(b []byte) (int, error) {
+	return c.WriteTo(b, c.remote)
+}
+
+// Close closes the packetConn and unbinds it from its address.
+func (c *packetConn) Close() error {
+	c.once.Do(func() {
+		conns.close(c)
+		close(c.closed)
+	})
+	return nil
+}
+
+// LocalAddr returns the address the packetConn is bound to.
+func (c *packetConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+// RemoteAddr returns the remote address of the packetConn, or nil if it was not opened with one.
+func (c *packetConn) RemoteAddr() net.Addr {
+	if c.remote == nil {
+		return nil
+	}
+	return c.remote
+}
+
+// SetDeadline sets the read deadline of the packetConn. Writes never block for long, so they have no deadline.
+func (c *packetConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline ...
+func (c *packetConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadline = t
+	close(c.deadlineChanged)
+	c.deadlineChanged = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline ...
+func (c *packetConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// error wraps an error in a *net.OpError for the operation passed.
+func (c *packetConn) error(op string, err error) error {
+	return &net.OpError{Op: op, Net: "udp", Source: c.addr, Addr: c.RemoteAddr(), Err: err}
+}
@@ -0,0 +1,156 @@
+package verstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sandertv/go-raknet"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Dial dials a listener on the in-memory network as a client speaking the protocol passed. Dial completes the
+// login sequence, resource packs and StartGame before returning, after which packets may be read from and
+// written to the connection returned. Packets read and written are always those of the latest protocol, as
+// they are converted from and to the protocol passed by the connection.
+// The listener must be started with authentication disabled, as the client does not log in to XBOX Live.
+func Dial(address string, proto minecraft.Protocol) (*minecraft.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	return DialContext(ctx, address, proto)
+}
+
+// DialContext dials a listener on the in-memory network in the same way as Dial, using the deadline of the
+// context.Context passed for the maximum amount of time that joining can take.
+func DialContext(ctx context.Context, address string, proto minecraft.Protocol) (*minecraft.Conn, error) {
+	conn, err := minecraft.Dialer{Protocol: proto}.DialContext(ctx, Network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.DoSpawnContext(ctx); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Accepts checks if a listener on the in-memory network accepts clients using the protocol passed. It sends the
+// RequestNetworkSettings packet that starts the login sequence and returns true if the listener responds with
+// NetworkSettings, or false if it responds with another packet rejecting the client. Unlike Dial, Accepts does not
+// use minecraft.Dialer, which may crash the process when a dial fails because the protocol is not accepted.
+func Accepts(address string, proto minecraft.Protocol) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	c, err := dialRaw(ctx, address)
+	if err != nil {
+		return false, err
+	}
+	defer c.conn.Close()
+
+	settings, err := c.requestNetworkSettings(proto)
+	return settings != nil, err
+}
+
+// Rejected joins a listener on the in-memory network as a client speaking the protocol passed, which the
+// listener is expected to reject before the client logs in. It returns the packets that the listener sent in
+// response to the RequestNetworkSettings packet, converted to the latest protocol, and returns an error if the
+// listener did not close the connection afterwards. Like Accepts, Rejected does not use minecraft.Dialer.
+func Rejected(address string, proto minecraft.Protocol) ([]packet.Packet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	c, err := dialRaw(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer c.conn.Close()
+
+	if err := c.write(&packet.RequestNetworkSettings{ClientProtocol: proto.ID()}); err != nil {
+		return nil, err
+	}
+	var (
+		pool    = proto.Packets(false)
+		packets []packet.Packet
+	)
+	for {
+		batch, err := c.dec.Decode()
+		if err != nil {
+			if ctx.Err() != nil {
+				return packets, fmt.Errorf("connection was not closed after RequestNetworkSettings")
+			}
+			return packets, nil
+		}
+		for _, data := range batch {
+			buf := bytes.NewBuffer(data)
+			header := &packet.Header{}
+			if err := header.Read(buf); err != nil {
+				return packets, err
+			}
+			f, ok := pool[header.PacketID]
+			if !ok {
+				return packets, fmt.Errorf("unknown packet %v", header.PacketID)
+			}
+			pk := f()
+			pk.Marshal(proto.NewReader(buf, 0, false))
+			packets = append(packets, proto.ConvertToLatest(pk, nil)...)
+		}
+	}
+}
+
+// rawConn is a RakNet connection to a listener that packets are encoded and decoded on directly, without the
+// minecraft.Conn that minecraft.Dialer would use.
+type rawConn struct {
+	conn net.Conn
+	enc  *packet.Encoder
+	dec  *packet.Decoder
+}
+
+// dialRaw dials a RakNet connection to the listener on the in-memory network at the address passed. The read
+// deadline of the connection is set to the deadline of the context.Context passed.
+func dialRaw(ctx context.Context, address string) (*rawConn, error) {
+	conn, err := raknet.Dialer{UpstreamDialer: dialer{}}.DialContext(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetReadDeadline(deadline)
+	return &rawConn{conn: conn, enc: packet.NewEncoder(conn), dec: packet.NewDecoder(conn)}, nil
+}
+
+// write writes a single packet of the latest protocol to the connection.
+func (c *rawConn) write(pk packet.Packet) error {
+	buf := bytes.NewBuffer(nil)
+	header := &packet.Header{PacketID: pk.ID()}
+	_ = header.Write(buf)
+	pk.Marshal(protocol.NewWriter(buf, 0))
+	return c.enc.Encode([][]byte{buf.Bytes()})
+}
+
+// requestNetworkSettings sends the RequestNetworkSettings packet that starts the login sequence. It returns the
+// NetworkSettings that the listener responds with, or nil if it responds with another packet rejecting the
+// client, such as a PlayStatus.
+func (c *rawConn) requestNetworkSettings(proto minecraft.Protocol) (*packet.NetworkSettings, error) {
+	if err := c.write(&packet.RequestNetworkSettings{ClientProtocol: proto.ID()}); err != nil {
+		return nil, err
+	}
+	packets, err := c.dec.Decode()
+	if err != nil {
+		return nil, err
+	} else if len(packets) == 0 {
+		return nil, fmt.Errorf("expected a packet in response to RequestNetworkSettings")
+	}
+	buf := bytes.NewBuffer(packets[0])
+	header := &packet.Header{}
+	if err := header.Read(buf); err != nil {
+		return nil, err
+	}
+	if header.PacketID != packet.IDNetworkSettings {
+		return nil, nil
+	}
+	settings := &packet.NetworkSettings{}
+	settings.Marshal(protocol.NewReader(buf, 0, false))
+	return settings, nil
+}
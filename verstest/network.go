@@ -0,0 +1,140 @@
+package verstest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/sandertv/go-raknet"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Network is the ID under which the in-memory network is registered with minecraft.RegisterNetwork. It may be
+// passed to vers.WithNetwork to make a Vers instance listen on the in-memory network.
+const Network = "verstest"
+
+// Net is the in-memory minecraft.Network registered under the ID Network.
+var Net minecraft.Network = network{}
+
+// network is a minecraft.Network that runs RakNet over in-process packet connections instead of UDP sockets, so
+// that no real port is bound and no traffic leaves the process. RakNet cannot be left out: the listener of the
+// gophertunnel fork asserts every accepted connection to be a *raknet.Conn.
+type network struct{}
+
+// DialContext ...
+func (network) DialContext(ctx context.Context, address string) (net.Conn, error) {
+	return raknet.Dialer{UpstreamDialer: dialer{}}.DialContext(ctx, address)
+}
+
+// PingContext ...
+func (network) PingContext(ctx context.Context, address string) ([]byte, error) {
+	return raknet.Dialer{UpstreamDialer: dialer{}}.PingContext(ctx, address)
+}
+
+// Listen ...
+func (network) Listen(address string) (minecraft.NetworkListener, error) {
+	return raknet.ListenConfig{UpstreamPacketListener: packetListener{}}.Listen(address)
+}
+
+// Compression ...
+func (network) Compression(net.Conn) packet.Compression { return packet.FlateCompression }
+
+// init registers the in-memory network.
+func init() {
+	minecraft.RegisterNetwork(Network, Net)
+}
+
+// dialer implements raknet.UpstreamDialer by creating in-memory connections to listeners in the hub.
+type dialer struct{}
+
+// Dial ...
+func (dialer) Dial(_, address string) (net.Conn, error) {
+	raddr, err := resolve(address)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := conns.lookup(raddr); !ok {
+		return nil, fmt.Errorf("dial %v: no listener on address", address)
+	}
+	return conns.open(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, raddr)
+}
+
+// packetListener implements raknet.UpstreamPacketListener by opening in-memory packet connections in the hub.
+type packetListener struct{}
+
+// ListenPacket ...
+func (packetListener) ListenPacket(_, address string) (net.PacketConn, error) {
+	addr, err := resolve(address)
+	if err != nil {
+		return nil, err
+	}
+	return conns.open(addr, nil)
+}
+
+// resolve resolves an address in the format accepted by net.ResolveUDPAddr. An empty or unspecified host is
+// resolved to the loopback address of the same IP version, as every connection of the in-memory network is local.
+func resolve(address string) (*net.UDPAddr, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	if addr.IP == nil || addr.IP.Equal(net.IPv4zero) {
+		addr.IP = net.IPv4(127, 0, 0, 1)
+	} else if addr.IP.Equal(net.IPv6unspecified) {
+		addr.IP = net.IPv6loopback
+	}
+	return addr, nil
+}
+
+// conns holds all open in-memory packet connections of the process.
+var conns = &hub{conns: map[string]*packetConn{}, port: 1 << 15}
+
+// hub keeps track of open packet connections by their local address, so that datagrams written to an address
+// may be delivered to the connection bound to it.
+type hub struct {
+	mu    sync.Mutex
+	conns map[string]*packetConn
+	port  int
+}
+
+// open opens a new packet connection bound to the address passed. If the port of the address is 0, a free port
+// is picked. If remote is non-nil, the connection is connected to the remote address for use as a net.Conn.
+func (h *hub) open(addr, remote *net.UDPAddr) (*packetConn, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	addr = &net.UDPAddr{IP: addr.IP, Port: addr.Port}
+	if addr.Port == 0 {
+		for {
+			h.port++
+			addr.Port = h.port
+			if _, ok := h.conns[addr.String()]; !ok {
+				break
+			}
+		}
+	} else if _, ok := h.conns[addr.String()]; ok {
+		return nil, fmt.Errorf("listen %v: address already in use", addr)
+	}
+	conn := newPacketConn(addr, remote)
+	h.conns[addr.String()] = conn
+	return conn, nil
+}
+
+// lookup looks up the packet connection bound to the address passed.
+func (h *hub) lookup(addr net.Addr) (*packetConn, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conn, ok := h.conns[addr.String()]
+	return conn, ok
+}
+
+// close removes the packet connection passed from the hub.
+func (h *hub) close(conn *packetConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.conns[conn.addr.String()] == conn {
+		delete(h.conns, conn.addr.String())
+	}
+}